@@ -11,6 +11,7 @@ import (
 	"github.com/joho/godotenv"
 	"github.com/sirupsen/logrus"
 	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
 )
 
 func exampleFlow(ctx jet.RenderContext, props jet.FlowProps) (*jet.RenderedFlow, error) {
@@ -69,6 +70,9 @@ func work() error {
 		AddMessageShortcut("jet_message", func(ctx jet.Context, args slack.InteractionCallback) error {
 			panic("modal")
 		}).
+		HandleAppMention(func(ctx jet.Context, event *slackevents.AppMentionEvent) error {
+			return ctx.StartFlowAndPost(f1, nil)
+		}).
 		Build(jet.Options{
 			Credentials: jet.Credentials{
 				SigningSecret: os.Getenv("SLACK_SIGNING_SECRET"),
@@ -83,6 +87,7 @@ func work() error {
 	http.Handle("/slack", handlers.SlashCommands)
 	http.Handle("/slack-interactive", handlers.Interactivity)
 	http.Handle("/slack-select", handlers.SelectMenus)
+	http.Handle("/slack-events", handlers.Events)
 	return http.ListenAndServe("localhost:8080", nil)
 }
 
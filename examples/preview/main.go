@@ -0,0 +1,70 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/LouisBrunner/jet/jet"
+	"github.com/LouisBrunner/jet/jet/jetpreview"
+	"github.com/slack-go/slack"
+)
+
+func counterFlow(ctx jet.RenderContext, props jet.FlowProps) (*jet.RenderedFlow, error) {
+	value, setValue, err := jet.UseState(ctx, 0)
+	if err != nil {
+		return nil, err
+	}
+	increment, err := jet.UseCallback(ctx, func(ctx context.Context, args slack.BlockAction) error {
+		return setValue(value + 1)
+	})
+	if err != nil {
+		return nil, err
+	}
+	reset, err := jet.UseCallback(ctx, func(ctx context.Context, args slack.BlockAction) error {
+		return setValue(0)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	text := fmt.Sprintf("%s: %d", props["label"], value)
+	return &jet.RenderedFlow{
+		Text: text,
+		Blocks: slack.Blocks{
+			BlockSet: []slack.Block{
+				slack.NewSectionBlock(slack.NewTextBlockObject("mrkdwn", text, false, false), nil, nil),
+				slack.NewActionBlock("",
+					slack.NewButtonBlockElement(increment, "", slack.NewTextBlockObject("plain_text", "+1", false, false)),
+					slack.NewButtonBlockElement(reset, "", slack.NewTextBlockObject("plain_text", "Reset", false, false)),
+				),
+			},
+		},
+	}, nil
+}
+
+func main() {
+	counter := jet.NewFlow("counter", counterFlow, nil)
+
+	render, err := jet.RenderForTest(counter, jet.FlowProps{"label": "Counter"}, nil)
+	if err != nil {
+		panic(err)
+	}
+	url, err := jetpreview.BuilderURL(render, "")
+	if err != nil {
+		panic(err)
+	}
+	fmt.Printf("Block Kit Builder: %s\n", url)
+
+	server := jetpreview.NewServer(
+		jetpreview.Preview{Name: "counter", Flow: counter, Props: jet.FlowProps{"label": "Counter"}},
+		jetpreview.Preview{Name: "counter-started", Flow: counter, Props: jet.FlowProps{"label": "Started"}, Options: &jet.TestRenderOptions{
+			State: map[int]any{0: 41},
+		}},
+	)
+	fmt.Println("Preview server: http://localhost:8081")
+	err = http.ListenAndServe("localhost:8081", server)
+	if err != nil {
+		panic(err)
+	}
+}
@@ -4,5 +4,7 @@ type Handlers[T any] struct {
 	SlashCommands T
 	Interactivity T
 	SelectMenus   T
+	Install       T
 	OAuth         T
+	Events        T
 }
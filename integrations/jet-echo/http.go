@@ -2,8 +2,9 @@ package jetecho
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
-	"fmt"
+	"errors"
 	"io"
 	"net/http"
 
@@ -11,6 +12,7 @@ import (
 	"github.com/LouisBrunner/jet/jet"
 	"github.com/labstack/echo/v4"
 	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
 )
 
 func signingVerifyMiddleware(app jet.App) echo.MiddlewareFunc {
@@ -61,7 +63,9 @@ func New(app jet.App) Handlers {
 		SlashCommands: handleSlashCommands(app, middlewares),
 		Interactivity: handleInteractivity(app, middlewares),
 		SelectMenus:   handleSelectMenus(app, middlewares),
+		Install:       handleInstall(app),
 		OAuth:         handleOAuth(app),
+		Events:        handleEvents(app, middlewares),
 	}
 }
 
@@ -89,47 +93,116 @@ func handleSlashCommands(app jet.App, middlewares []echo.MiddlewareFunc) EchoAdd
 }
 
 func handleInteractivity(app jet.App, middlewares []echo.MiddlewareFunc) EchoAdder {
+	return handleInteractionPayload(app, "interactivity", middlewares)
+}
+
+func handleSelectMenus(app jet.App, middlewares []echo.MiddlewareFunc) EchoAdder {
+	return handleInteractionPayload(app, "select menu", middlewares)
+}
+
+func handleInteractionPayload(app jet.App, kind string, middlewares []echo.MiddlewareFunc) EchoAdder {
 	return func(e EchoRoutes, path string, theirMiddlewares ...echo.MiddlewareFunc) *echo.Route {
 		return e.POST(path, func(c echo.Context) error {
-			app.LogDebugf("interactivity: %+v", c)
+			app.LogDebugf("%s: %+v", kind, c)
 
 			payload := c.FormValue("payload")
 			if payload == "" {
-				app.LogErrorf("missing payload in interactivity request")
+				app.LogErrorf("missing payload in %s request", kind)
 				return c.String(http.StatusBadRequest, "")
 			}
 
 			var args slack.InteractionCallback
 			err := json.Unmarshal([]byte(payload), &args)
 			if err != nil {
-				app.LogErrorf("failed to unmarshal interactivity payload: %+v", err)
+				app.LogErrorf("failed to unmarshal %s payload: %+v", kind, err)
 				return c.String(http.StatusBadRequest, "")
 			}
 
-			err = app.HandleInteraction(c.Request().Context(), args)
+			res, err := app.HandleInteraction(c.Request().Context(), args)
+			if errors.Is(err, jet.ErrUnsupportedInteraction) {
+				app.LogErrorf("ignoring %s: %+v", kind, err)
+				return c.String(http.StatusOK, "")
+			}
 			if err != nil {
-				app.LogErrorf("failed to handle interactivity: %+v", err)
+				app.LogErrorf("failed to handle %s: %+v", kind, err)
 				return c.String(http.StatusInternalServerError, "")
 			}
+			if res == nil {
+				return c.String(http.StatusOK, "")
+			}
 
-			return c.String(http.StatusOK, "")
+			app.LogDebugf("%s response: %+v", kind, res)
+			return c.JSON(http.StatusOK, res)
 		}, append(theirMiddlewares, middlewares...)...)
 	}
 }
 
-func handleSelectMenus(app jet.App, middlewares []echo.MiddlewareFunc) EchoAdder {
+func handleEvents(app jet.App, middlewares []echo.MiddlewareFunc) EchoAdder {
 	return func(e EchoRoutes, path string, theirMiddlewares ...echo.MiddlewareFunc) *echo.Route {
 		return e.POST(path, func(c echo.Context) error {
-			app.LogDebugf("select menu: %+v", c)
+			app.LogDebugf("events: %+v", c)
 
-			// TODO: finish
-			a, _ := io.ReadAll(c.Request().Body)
-			fmt.Printf("select menu: %+v\n", string(a))
-			return fmt.Errorf("not implemented")
+			body, err := io.ReadAll(c.Request().Body)
+			if err != nil {
+				return c.String(http.StatusBadRequest, "")
+			}
+
+			// the signature was already checked by signingVerifyMiddleware, which supersedes the verification token
+			event, err := slackevents.ParseEvent(json.RawMessage(body), slackevents.OptionNoVerifyToken())
+			if err != nil {
+				app.LogErrorf("failed to parse event: %+v", err)
+				return c.String(http.StatusBadRequest, "")
+			}
+
+			if retry := c.Request().Header.Get("X-Slack-Retry-Num"); retry != "" {
+				app.LogDebugf("event retry %s: %s", retry, c.Request().Header.Get("X-Slack-Retry-Reason"))
+			}
+
+			// Slack expects an answer within 3 seconds and retries otherwise, so callback events are acknowledged before being handled
+			if event.Type == slackevents.CallbackEvent {
+				ctx := context.WithoutCancel(c.Request().Context())
+				go func() {
+					_, err := app.HandleEvent(ctx, event)
+					if err != nil {
+						app.LogErrorf("failed to handle event: %+v", err)
+					}
+				}()
+				return c.String(http.StatusOK, "")
+			}
+
+			res, err := app.HandleEvent(c.Request().Context(), event)
+			if err != nil {
+				app.LogErrorf("failed to handle event: %+v", err)
+				return c.String(http.StatusInternalServerError, "")
+			}
+			if res == nil {
+				return c.String(http.StatusOK, "")
+			}
+
+			app.LogDebugf("event response: %+v", res)
+			return c.JSON(http.StatusOK, res)
 		}, append(theirMiddlewares, middlewares...)...)
 	}
 }
 
+func handleInstall(app jet.App) EchoAdder {
+	if app.Options().OAuthConfig == nil {
+		return nil
+	}
+
+	return func(e EchoRoutes, path string, middlewares ...echo.MiddlewareFunc) *echo.Route {
+		return e.GET(path, func(c echo.Context) error {
+			app.LogDebugf("install: %+v", c)
+
+			state := c.QueryParam("state")
+
+			handler := app.StartOAuth(c.Request().Context(), state)
+			handler.ServeHTTP(c.Response(), c.Request())
+			return nil
+		}, middlewares...)
+	}
+}
+
 func handleOAuth(app jet.App) EchoAdder {
 	if app.Options().OAuthConfig == nil {
 		return nil
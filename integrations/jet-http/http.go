@@ -2,14 +2,16 @@ package jethttp
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
-	"fmt"
+	"errors"
 	"io"
 	"net/http"
 
 	"github.com/LouisBrunner/jet/integrations/common"
 	"github.com/LouisBrunner/jet/jet"
 	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
 )
 
 func verifyRequest(app jet.App, w http.ResponseWriter, r *http.Request) bool {
@@ -48,7 +50,9 @@ func New(app jet.App) Handlers {
 		SlashCommands: handleSlashCommands(app),
 		Interactivity: handleInteractivity(app),
 		SelectMenus:   handleSelectMenus(app),
+		Install:       handleInstall(app),
 		OAuth:         handleOAuth(app),
+		Events:        handleEvents(app),
 	}
 }
 
@@ -72,36 +76,48 @@ func handleSlashCommands(app jet.App) http.Handler {
 			w.WriteHeader(http.StatusOK)
 			return
 		}
-		app.LogDebugf("slash response: %+v", res)
+		writeJSON(app, w, "slash", res)
+	})
+}
 
-		json, err := json.Marshal(res)
-		if err != nil {
-			app.LogErrorf("failed to marshal slash response: %+v", err)
-			w.WriteHeader(http.StatusInternalServerError)
-			return
-		}
-		app.LogDebugf("slash response json: %+v", string(json))
+func writeJSON(app jet.App, w http.ResponseWriter, kind string, res any) {
+	app.LogDebugf("%s response: %+v", kind, res)
 
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusOK)
-		_, err = w.Write([]byte(json))
-		if err != nil {
-			app.LogErrorf("failed to write slash response: %+v", err)
-			return
-		}
-	})
+	json, err := json.Marshal(res)
+	if err != nil {
+		app.LogErrorf("failed to marshal %s response: %+v", kind, err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	app.LogDebugf("%s response json: %+v", kind, string(json))
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_, err = w.Write([]byte(json))
+	if err != nil {
+		app.LogErrorf("failed to write %s response: %+v", kind, err)
+		return
+	}
 }
 
 func handleInteractivity(app jet.App) http.Handler {
+	return handleInteractionPayload(app, "interactivity")
+}
+
+func handleSelectMenus(app jet.App) http.Handler {
+	return handleInteractionPayload(app, "select menu")
+}
+
+func handleInteractionPayload(app jet.App, kind string) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		app.LogDebugf("interactivity: %+v", r.Header)
+		app.LogDebugf("%s: %+v", kind, r.Header)
 		if !verifyRequest(app, w, r) {
 			return
 		}
 
 		payload := r.PostFormValue("payload")
 		if payload == "" {
-			app.LogErrorf("missing payload in interactivity request")
+			app.LogErrorf("missing payload in %s request", kind)
 			w.WriteHeader(http.StatusBadRequest)
 			return
 		}
@@ -109,33 +125,93 @@ func handleInteractivity(app jet.App) http.Handler {
 		var args slack.InteractionCallback
 		err := json.Unmarshal([]byte(payload), &args)
 		if err != nil {
-			app.LogErrorf("failed to unmarshal interactivity payload: %+v", err)
+			app.LogErrorf("failed to unmarshal %s payload: %+v", kind, err)
 			w.WriteHeader(http.StatusBadRequest)
 			return
 		}
 
-		err = app.HandleInteraction(r.Context(), args)
+		res, err := app.HandleInteraction(r.Context(), args)
+		if errors.Is(err, jet.ErrUnsupportedInteraction) {
+			app.LogErrorf("ignoring %s: %+v", kind, err)
+			w.WriteHeader(http.StatusOK)
+			return
+		}
 		if err != nil {
-			app.LogErrorf("failed to handle interactivity: %+v", err)
+			app.LogErrorf("failed to handle %s: %+v", kind, err)
 			w.WriteHeader(http.StatusInternalServerError)
 			return
 		}
-
-		w.WriteHeader(http.StatusOK)
+		if res == nil {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		writeJSON(app, w, kind, res)
 	})
 }
 
-func handleSelectMenus(app jet.App) http.Handler {
+func handleEvents(app jet.App) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		app.LogDebugf("select menu: %+v", r.Header)
+		app.LogDebugf("events: %+v", r.Header)
 		if !verifyRequest(app, w, r) {
 			return
 		}
 
-		// TODO: finish
-		a, _ := io.ReadAll(r.Body)
-		fmt.Printf("select menu: %+v\n", r.Header)
-		fmt.Printf("select menu: %+v\n", string(a))
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		// the signature was already checked by verifyRequest, which supersedes the verification token
+		event, err := slackevents.ParseEvent(json.RawMessage(body), slackevents.OptionNoVerifyToken())
+		if err != nil {
+			app.LogErrorf("failed to parse event: %+v", err)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		if retry := r.Header.Get("X-Slack-Retry-Num"); retry != "" {
+			app.LogDebugf("event retry %s: %s", retry, r.Header.Get("X-Slack-Retry-Reason"))
+		}
+
+		// Slack expects an answer within 3 seconds and retries otherwise, so callback events are acknowledged before being handled
+		if event.Type == slackevents.CallbackEvent {
+			ctx := context.WithoutCancel(r.Context())
+			w.WriteHeader(http.StatusOK)
+			go func() {
+				_, err := app.HandleEvent(ctx, event)
+				if err != nil {
+					app.LogErrorf("failed to handle event: %+v", err)
+				}
+			}()
+			return
+		}
+
+		res, err := app.HandleEvent(r.Context(), event)
+		if err != nil {
+			app.LogErrorf("failed to handle event: %+v", err)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		if res == nil {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		writeJSON(app, w, "event", res)
+	})
+}
+
+func handleInstall(app jet.App) http.Handler {
+	if app.Options().OAuthConfig == nil {
+		return nil
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		app.LogDebugf("install: %+v", r.Header)
+
+		state := r.URL.Query().Get("state")
+
+		app.StartOAuth(r.Context(), state).ServeHTTP(w, r)
 	})
 }
 
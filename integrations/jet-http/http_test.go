@@ -0,0 +1,103 @@
+package jethttp
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/LouisBrunner/jet/jet"
+	"github.com/slack-go/slack/slackevents"
+)
+
+const signingSecret = "secret"
+
+type testLogger struct {
+	t *testing.T
+}
+
+func (me testLogger) Errorf(format string, v ...interface{}) {
+	me.t.Logf("error: "+format, v...)
+}
+
+func (me testLogger) Debugf(format string, v ...interface{}) {}
+
+func signedRequest(t *testing.T, body any) *http.Request {
+	raw, err := json.Marshal(body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ts := fmt.Sprint(time.Now().Unix())
+	mac := hmac.New(sha256.New, []byte(signingSecret))
+	fmt.Fprintf(mac, "v0:%s:%s", ts, raw)
+	req := httptest.NewRequest(http.MethodPost, "/events", strings.NewReader(string(raw)))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Slack-Request-Timestamp", ts)
+	req.Header.Set("X-Slack-Signature", "v0="+hex.EncodeToString(mac.Sum(nil)))
+	return req
+}
+
+func TestEvents(t *testing.T) {
+	release := make(chan struct{})
+	handled := make(chan string, 1)
+	app := jet.NewBuilder().HandleEvent("app_mention", func(ctx jet.Context, event slackevents.EventsAPIEvent) error {
+		<-release
+		handled <- event.InnerEvent.Type
+		return ctx.Err()
+	}).Build(jet.Options{
+		Credentials: jet.Credentials{SigningSecret: signingSecret},
+		Logger:      testLogger{t},
+	})
+	handler := New(app).Events
+
+	t.Run("url verification", func(t *testing.T) {
+		res := httptest.NewRecorder()
+		handler.ServeHTTP(res, signedRequest(t, map[string]any{"type": "url_verification", "challenge": "abc"}))
+		body, _ := io.ReadAll(res.Body)
+		if res.Code != http.StatusOK || !strings.Contains(string(body), `"challenge":"abc"`) {
+			t.Fatalf("unexpected response %d: %s", res.Code, body)
+		}
+	})
+
+	t.Run("unsigned", func(t *testing.T) {
+		req := signedRequest(t, map[string]any{"type": "url_verification", "challenge": "abc"})
+		req.Header.Set("X-Slack-Signature", "v0="+strings.Repeat("0", 64))
+		res := httptest.NewRecorder()
+		handler.ServeHTTP(res, req)
+		if res.Code != http.StatusUnauthorized {
+			t.Fatalf("unexpected response %d", res.Code)
+		}
+	})
+
+	t.Run("acked before being handled", func(t *testing.T) {
+		req := signedRequest(t, map[string]any{
+			"type":    "event_callback",
+			"team_id": "T1",
+			"event":   map[string]any{"type": "app_mention", "user": "U1", "channel": "C1", "text": "hi"},
+		})
+		req.Header.Set("X-Slack-Retry-Num", "1")
+		req.Header.Set("X-Slack-Retry-Reason", "http_timeout")
+		res := httptest.NewRecorder()
+		handler.ServeHTTP(res, req)
+		if res.Code != http.StatusOK || res.Body.Len() != 0 {
+			t.Fatalf("unexpected response %d: %s", res.Code, res.Body)
+		}
+
+		close(release)
+		select {
+		case kind := <-handled:
+			if kind != "app_mention" {
+				t.Fatalf("unexpected event %q", kind)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("event was not handled")
+		}
+	})
+}
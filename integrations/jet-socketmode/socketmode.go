@@ -0,0 +1,145 @@
+package jetsocketmode
+
+import (
+	"context"
+	"errors"
+
+	"github.com/LouisBrunner/jet/jet"
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+	"github.com/slack-go/slack/socketmode"
+)
+
+type Options struct {
+	// The app-level token (`xapp-...`) with the `connections:write` scope.
+	AppToken string
+	// Extra options for the Slack client used to open the connection (e.g. `slack.OptionAPIURL` to use a local stand-in).
+	SlackOptions []slack.Option
+	// Extra options for the Socket Mode client.
+	SocketModeOptions []socketmode.Option
+}
+
+type Client struct {
+	app    jet.App
+	client *socketmode.Client
+}
+
+func New(app jet.App, opts Options) *Client {
+	api := slack.New("", append([]slack.Option{slack.OptionAppLevelToken(opts.AppToken)}, opts.SlackOptions...)...)
+	return &Client{
+		app:    app,
+		client: socketmode.New(api, opts.SocketModeOptions...),
+	}
+}
+
+// Run connects to Slack and forwards all requests to the App until the context is canceled or the connection fails.
+func (me *Client) Run(ctx context.Context) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	errs := make(chan error, 1)
+	go func() {
+		errs <- me.client.RunContext(ctx)
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case err := <-errs:
+			return err
+		case evt := <-me.client.Events:
+			go me.handle(ctx, evt)
+		}
+	}
+}
+
+func (me *Client) handle(ctx context.Context, evt socketmode.Event) {
+	switch evt.Type {
+	case socketmode.EventTypeConnecting, socketmode.EventTypeConnected, socketmode.EventTypeHello, socketmode.EventTypeDisconnect:
+		me.app.LogDebugf("socket mode: %s", evt.Type)
+	case socketmode.EventTypeConnectionError, socketmode.EventTypeInvalidAuth, socketmode.EventTypeIncomingError, socketmode.EventTypeErrorWriteFailed, socketmode.EventTypeErrorBadMessage:
+		me.app.LogErrorf("socket mode: %s: %+v", evt.Type, evt.Data)
+	case socketmode.EventTypeSlashCommand:
+		me.handleSlashCommand(ctx, evt)
+	case socketmode.EventTypeInteractive:
+		me.handleInteraction(ctx, evt)
+	case socketmode.EventTypeEventsAPI:
+		me.handleEvent(ctx, evt)
+	default:
+		me.app.LogDebugf("socket mode: ignoring %s", evt.Type)
+	}
+}
+
+// ack must be called for every request, otherwise Slack keeps retrying it and shows a timeout to the user
+func (me *Client) ack(ctx context.Context, evt socketmode.Event, kind string, payload any) {
+	if evt.Request == nil {
+		me.app.LogErrorf("cannot ack %s without a request: %+v", kind, evt)
+		return
+	}
+	err := me.client.AckCtx(ctx, evt.Request.EnvelopeID, payload)
+	if err != nil {
+		me.app.LogErrorf("failed to ack %s: %+v", kind, err)
+	}
+}
+
+func (me *Client) handleSlashCommand(ctx context.Context, evt socketmode.Event) {
+	args, ok := evt.Data.(slack.SlashCommand)
+	if !ok {
+		me.app.LogErrorf("invalid slash command: %+v", evt)
+		me.ack(ctx, evt, "slash command", nil)
+		return
+	}
+
+	res := me.app.HandleSlashCommand(ctx, args)
+	if res == nil {
+		me.app.LogDebugf("slash response: nil")
+		me.ack(ctx, evt, "slash command", nil)
+		return
+	}
+	me.app.LogDebugf("slash response: %+v", res)
+	me.ack(ctx, evt, "slash command", res)
+}
+
+func (me *Client) handleInteraction(ctx context.Context, evt socketmode.Event) {
+	args, ok := evt.Data.(slack.InteractionCallback)
+	if !ok {
+		me.app.LogErrorf("invalid interaction: %+v", evt)
+		me.ack(ctx, evt, "interaction", nil)
+		return
+	}
+
+	res, err := me.app.HandleInteraction(ctx, args)
+	if errors.Is(err, jet.ErrUnsupportedInteraction) {
+		me.app.LogErrorf("ignoring interaction: %+v", err)
+		me.ack(ctx, evt, "interaction", nil)
+		return
+	}
+	if err != nil {
+		// the error is only logged, an empty ack stops Slack from retrying a request which will keep failing
+		me.app.LogErrorf("failed to handle interaction: %+v", err)
+		me.ack(ctx, evt, "interaction", nil)
+		return
+	}
+	if res != nil {
+		me.app.LogDebugf("interaction response: %+v", res)
+	}
+	me.ack(ctx, evt, "interaction", res)
+}
+
+func (me *Client) handleEvent(ctx context.Context, evt socketmode.Event) {
+	event, ok := evt.Data.(slackevents.EventsAPIEvent)
+	if !ok {
+		me.app.LogErrorf("invalid event: %+v", evt)
+		me.ack(ctx, evt, "event", nil)
+		return
+	}
+
+	// events don't carry a response, acknowledge first so slow handlers don't trigger retries
+	me.ack(ctx, evt, "event", nil)
+
+	_, err := me.app.HandleEvent(ctx, event)
+	if err != nil {
+		me.app.LogErrorf("failed to handle event %s: %+v", event.InnerEvent.Type, err)
+	}
+}
@@ -0,0 +1,156 @@
+package jetsocketmode
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/LouisBrunner/jet/jet"
+	"github.com/gorilla/websocket"
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+)
+
+type envelope struct {
+	EnvelopeID string          `json:"envelope_id"`
+	Type       string          `json:"type"`
+	Payload    json.RawMessage `json:"payload"`
+}
+
+// standIn is a local replacement for the Socket Mode endpoint of Slack, it sends the given requests once connected and collects the acks
+func standIn(t *testing.T, requests []envelope) (string, <-chan envelope) {
+	acks := make(chan envelope, len(requests))
+	upgrader := websocket.Upgrader{
+		// the client always sends the origin of Slack
+		CheckOrigin: func(r *http.Request) bool {
+			return true
+		},
+	}
+	mux := http.NewServeMux()
+	var srv *httptest.Server
+	mux.HandleFunc("POST /api/apps.connections.open", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"ok":  true,
+			"url": "ws" + strings.TrimPrefix(srv.URL, "http") + "/ws",
+		})
+	})
+	mux.HandleFunc("/ws", func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			t.Errorf("failed to upgrade: %v", err)
+			return
+		}
+		defer conn.Close()
+		err = conn.WriteJSON(map[string]any{"type": "hello"})
+		if err != nil {
+			return
+		}
+		for _, req := range requests {
+			err = conn.WriteJSON(req)
+			if err != nil {
+				return
+			}
+		}
+		for {
+			var ack envelope
+			err = conn.ReadJSON(&ack)
+			if err != nil {
+				return
+			}
+			acks <- ack
+		}
+	})
+	srv = httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv.URL + "/api/", acks
+}
+
+type testLogger struct {
+	t *testing.T
+}
+
+func (me testLogger) Errorf(format string, v ...interface{}) {
+	me.t.Logf("error: "+format, v...)
+}
+
+func (me testLogger) Debugf(format string, v ...interface{}) {}
+
+func mustJSON(t *testing.T, value any) json.RawMessage {
+	raw, err := json.Marshal(value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return raw
+}
+
+func TestAcks(t *testing.T) {
+	apiURL, acks := standIn(t, []envelope{
+		{EnvelopeID: "slash", Type: "slash_commands", Payload: mustJSON(t, map[string]any{"command": "/hello", "team_id": "T1", "user_id": "U1", "is_enterprise_install": "false"})},
+		{EnvelopeID: "unknown-slash", Type: "slash_commands", Payload: mustJSON(t, map[string]any{"command": "/unknown", "team_id": "T1", "is_enterprise_install": "false"})},
+		// fails as the message doesn't carry any jet metadata
+		{EnvelopeID: "failed-interaction", Type: "interactive", Payload: mustJSON(t, map[string]any{"type": "block_actions", "team": map[string]any{"id": "T1"}})},
+		{EnvelopeID: "unsupported-interaction", Type: "interactive", Payload: mustJSON(t, map[string]any{"type": "workflow_step_edit"})},
+		{EnvelopeID: "event", Type: "events_api", Payload: mustJSON(t, map[string]any{
+			"type":    "event_callback",
+			"team_id": "T1",
+			"event":   map[string]any{"type": "app_mention", "user": "U1", "channel": "C1", "text": "hi"},
+		})},
+	})
+
+	events := make(chan string, 1)
+	app := jet.NewBuilder().AddSlash("/hello", func(ctx jet.Context, args slack.SlashCommand) (*jet.Message, error) {
+		return jet.EphemeralTextMessage("hello"), nil
+	}).HandleEvent("app_mention", func(ctx jet.Context, event slackevents.EventsAPIEvent) error {
+		events <- event.InnerEvent.Type
+		return nil
+	}).Build(jet.Options{Logger: testLogger{t}})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	client := New(app, Options{
+		AppToken:     "xapp-test",
+		SlackOptions: []slack.Option{slack.OptionAPIURL(apiURL)},
+	})
+	go func() {
+		_ = client.Run(ctx)
+	}()
+
+	received := make(map[string]envelope)
+	timeout := time.After(5 * time.Second)
+	for len(received) < 5 {
+		select {
+		case ack := <-acks:
+			received[ack.EnvelopeID] = ack
+		case <-timeout:
+			t.Fatalf("timed out waiting for the acks, got %v", received)
+		}
+	}
+
+	var slash slack.Msg
+	err := json.Unmarshal(received["slash"].Payload, &slash)
+	if err != nil || slash.Text != "hello" {
+		t.Errorf("expected the slash response in the ack, got %s", received["slash"].Payload)
+	}
+	// errors of slash commands are formatted as a message
+	if !strings.Contains(string(received["unknown-slash"].Payload), "unknown command") {
+		t.Errorf("expected the error in the ack, got %s", received["unknown-slash"].Payload)
+	}
+	for _, id := range []string{"failed-interaction", "unsupported-interaction", "event"} {
+		if payload := string(received[id].Payload); payload != "" && payload != "null" {
+			t.Errorf("expected an empty ack for %q, got %s", id, payload)
+		}
+	}
+	select {
+	case kind := <-events:
+		if kind != "app_mention" {
+			t.Errorf("expected an app_mention event, got %q", kind)
+		}
+	case <-time.After(5 * time.Second):
+		t.Error("timed out waiting for the event to be handled")
+	}
+}
@@ -8,20 +8,29 @@ import (
 	"net/http"
 
 	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
 )
 
 type HomeUpdater = func(ctx Context) (*Message, error)
 
 type App interface {
 	HandleSlashCommand(ctx context.Context, slash slack.SlashCommand) *Message
-	HandleInteraction(ctx context.Context, interaction slack.InteractionCallback) error
-	// TODO: select menu
-	// TODO: workflow step
-	// TODO: bot events (e.g. reaction)
-	UpdateHome(ctx context.Context, workspaceID, userID string, updater HomeUpdater) error
-	SlackAPI(teamID string) (*slack.Client, error)
+	// the returned value, if not nil, must be sent back to Slack as JSON
+	HandleInteraction(ctx context.Context, interaction slack.InteractionCallback) (any, error)
+	// the returned value, if not nil, must be sent back to Slack as JSON
+	HandleEvent(ctx context.Context, event slackevents.EventsAPIEvent) (any, error)
+	// completes a workflow function (see `AppBuilder.AddWorkflowFunction`), e.g. from the callback of a flow it posted
+	CompleteWorkflowFunction(ctx context.Context, function WorkflowFunction, outputs map[string]string) error
+	FailWorkflowFunction(ctx context.Context, function WorkflowFunction, message string) error
+	// the enterprise ID is only needed on Enterprise Grid, where it finds the token of org-wide installs
+	UpdateHome(ctx context.Context, teamID, enterpriseID, userID string, updater HomeUpdater) error
+	SlackAPI(teamID, enterpriseID string) (*slack.Client, error)
+	// returns the token of the user who installed the app (requires an InstallationStore), it is refreshed if needed like the bot token
+	UserAccessToken(ctx context.Context, teamID, enterpriseID string) (string, error)
 	Options() Options
 
+	// redirects the user to Slack to install the app, the given state is passed back to OnSuccess once verified
+	StartOAuth(ctx context.Context, state string) http.Handler
 	FinalizeOAuth(ctx context.Context, code, state string) http.Handler
 
 	LogDebugf(format string, v ...interface{})
@@ -31,14 +40,24 @@ type App interface {
 }
 
 type app struct {
-	flows            map[FlowHandle]*Flow
-	slashes          map[string]SlashCommandHandler
-	unknownSlash     SlashCommandHandler
-	globalShortcuts  map[string]ShortcutHandler
-	messageShortcuts map[string]ShortcutHandler
-	viewSubmitted    map[string]ViewSubmittedHandler
-	unknownShortcut  ShortcutHandler
-	opts             Options
+	flows               map[FlowHandle]*Flow
+	slashes             map[string]SlashCommandHandler
+	unknownSlash        SlashCommandHandler
+	globalShortcuts     map[string]ShortcutHandler
+	messageShortcuts    map[string]ShortcutHandler
+	viewSubmitted       map[string]ViewSubmittedHandler
+	viewClosed          map[string]ViewClosedHandler
+	unknownShortcut     ShortcutHandler
+	dialogs             map[string]dialogHandlers
+	interactiveMessages map[string]InteractiveMessageHandler
+	unknownInteraction  InteractionHandler
+	events              map[string]EventHandler
+	workflowFunctions   map[string]WorkflowFunctionHandler
+	opts                Options
+	clients             clientCache
+	limits              rateLimits
+	tokenLocks          tokenLocks
+	oauthStateSecret    []byte
 }
 
 func (me *app) Options() Options {
@@ -52,13 +71,17 @@ func (me *app) HandleSlashCommand(ctx context.Context, slash slack.SlashCommand)
 		Context: ctx,
 		app:     me,
 		msgOpts: messageOptions{
-			TeamID:      slash.TeamID,
-			ChannelID:   slash.ChannelID,
-			ResponseURL: slash.ResponseURL,
+			TeamID:       slash.TeamID,
+			EnterpriseID: slash.EnterpriseID,
+			ChannelID:    slash.ChannelID,
+			ResponseURL:  slash.ResponseURL,
 		},
 		source: SourceInfo{
-			TeamID: slash.TeamID,
-			UserID: slash.UserID,
+			TeamID:       slash.TeamID,
+			EnterpriseID: slash.EnterpriseID,
+			UserID:       slash.UserID,
+			ChannelID:    slash.ChannelID,
+			TriggerID:    slash.TriggerID,
 		},
 	}
 
@@ -75,6 +98,15 @@ func (me *app) HandleSlashCommand(ctx context.Context, slash slack.SlashCommand)
 		res, err = cmd(appCtx, slash)
 	}
 
+	return me.respond(ctx, res, err)
+}
+
+// respond prepares the message sent back directly to Slack, errors are formatted with the ErrorFormatter
+func (me *app) respond(ctx context.Context, res *Message, err error) *Message {
+	if err == nil && res != nil {
+		res, err = me.encodeResponse(ctx, res)
+	}
+
 	if err != nil {
 		if me.opts.ErrorFormatter != nil {
 			msg := me.opts.ErrorFormatter(err)
@@ -92,33 +124,154 @@ func (me *app) HandleSlashCommand(ctx context.Context, slash slack.SlashCommand)
 	return res
 }
 
-func (me *app) HandleInteraction(ctx context.Context, interaction slack.InteractionCallback) error {
+// encodeResponse prepares the metadata of a message which is sent back directly in a response
+func (me *app) encodeResponse(ctx context.Context, res *Message) (*Message, error) {
+	msg, err := me.encodeMessage(ctx, &res.Msg)
+	if err != nil {
+		return nil, err
+	}
+	return &Message{
+		Msg:   *msg,
+		modal: res.modal,
+	}, nil
+}
+
+func (me *app) HandleInteraction(ctx context.Context, interaction slack.InteractionCallback) (any, error) {
 	me.LogDebugf("handling interaction: %+v", interaction)
 	switch interaction.Type {
-	case slack.InteractionTypeDialogCancellation:
-		panic("not implemented") // TODO: finish
-	case slack.InteractionTypeDialogSubmission:
-		panic("not implemented") // TODO: finish
-	case slack.InteractionTypeDialogSuggestion:
-		panic("not implemented") // TODO: finish
-	case slack.InteractionTypeInteractionMessage:
-		panic("not implemented") // TODO: finish
 	case slack.InteractionTypeMessageAction:
-		return me.handleShortcut(ctx, me.messageShortcuts, interaction)
+		return nil, me.handleShortcut(ctx, me.messageShortcuts, interaction)
 	case slack.InteractionTypeBlockActions:
-		return me.handleBlockActions(ctx, interaction)
+		return nil, me.handleBlockActions(ctx, interaction)
 	case slack.InteractionTypeBlockSuggestion:
-		panic("not implemented") // TODO: finish
+		return me.handleBlockSuggestion(ctx, interaction)
 	case slack.InteractionTypeViewSubmission:
 		return me.handleViewSubmission(ctx, interaction)
 	case slack.InteractionTypeViewClosed:
-		panic("not implemented") // TODO: finish
+		return nil, me.handleViewClosed(ctx, interaction)
 	case slack.InteractionTypeShortcut:
-		return me.handleShortcut(ctx, me.globalShortcuts, interaction)
-	case slack.InteractionTypeWorkflowStepEdit:
-		panic("not implemented") // TODO: finish
+		return nil, me.handleShortcut(ctx, me.globalShortcuts, interaction)
+	case slack.InteractionTypeInteractionMessage:
+		return me.handleInteractiveMessage(ctx, interaction)
+	case slack.InteractionTypeDialogSubmission, slack.InteractionTypeDialogCancellation, slack.InteractionTypeDialogSuggestion:
+		return me.handleDialog(ctx, interaction)
+	default:
+		return me.handleUnknownInteraction(ctx, interaction)
+	}
+}
+
+func (me *app) handleUnknownInteraction(ctx context.Context, interaction slack.InteractionCallback) (any, error) {
+	if me.unknownInteraction == nil {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedInteraction, interaction.Type)
+	}
+	return me.unknownInteraction(&appContext{
+		Context: ctx,
+		app:     me,
+		msgOpts: messageOptions{
+			TeamID:       interaction.Team.ID,
+			EnterpriseID: interaction.Enterprise.ID,
+			ChannelID:    interaction.Channel.ID,
+			ResponseURL:  interaction.ResponseURL,
+		},
+		source: SourceInfo{
+			TeamID:       interaction.Team.ID,
+			EnterpriseID: interaction.Enterprise.ID,
+			UserID:       interaction.User.ID,
+			ChannelID:    interaction.Channel.ID,
+			TriggerID:    interaction.TriggerID,
+		},
+	}, interaction)
+}
+
+func (me *app) handleInteractiveMessage(ctx context.Context, interaction slack.InteractionCallback) (any, error) {
+	handler, found := me.interactiveMessages[interaction.CallbackID]
+	if !found {
+		return me.handleUnknownInteraction(ctx, interaction)
+	}
+
+	res, err := handler(&appContext{
+		Context: ctx,
+		app:     me,
+		msgOpts: messageOptions{
+			TeamID:       interaction.Team.ID,
+			EnterpriseID: interaction.Enterprise.ID,
+			ChannelID:    interaction.Channel.ID,
+			ResponseURL:  interaction.ResponseURL,
+		},
+		source: SourceInfo{
+			TeamID:       interaction.Team.ID,
+			EnterpriseID: interaction.Enterprise.ID,
+			UserID:       interaction.User.ID,
+			ChannelID:    interaction.Channel.ID,
+			TriggerID:    interaction.TriggerID,
+		},
+	}, interaction)
+	if err == nil && res != nil && !res.DeleteOriginal {
+		// Slack posts a new message unless told otherwise
+		replacement := *res
+		replacement.ReplaceOriginal = true
+		res = &replacement
+	}
+
+	res = me.respond(ctx, res, err)
+	if res == nil {
+		return nil, nil
+	}
+	return res, nil
+}
+
+func (me *app) handleDialog(ctx context.Context, interaction slack.InteractionCallback) (any, error) {
+	handlers, found := me.dialogs[interaction.CallbackID]
+	if !found {
+		return me.handleUnknownInteraction(ctx, interaction)
+	}
+
+	appCtx := &appContext{
+		Context: ctx,
+		app:     me,
+		msgOpts: messageOptions{
+			TeamID:       interaction.Team.ID,
+			EnterpriseID: interaction.Enterprise.ID,
+			ChannelID:    interaction.Channel.ID,
+			ResponseURL:  interaction.ResponseURL,
+		},
+		source: SourceInfo{
+			TeamID:       interaction.Team.ID,
+			EnterpriseID: interaction.Enterprise.ID,
+			UserID:       interaction.User.ID,
+			ChannelID:    interaction.Channel.ID,
+			TriggerID:    interaction.TriggerID,
+		},
+	}
+
+	switch interaction.Type {
+	case slack.InteractionTypeDialogSubmission:
+		if handlers.submit == nil {
+			return nil, nil
+		}
+		err := handlers.submit(appCtx, interaction)
+		var dialogErrs DialogErrors
+		if errors.As(err, &dialogErrs) {
+			return dialogErrs.toSlack(), nil
+		}
+		return nil, err
+	case slack.InteractionTypeDialogCancellation:
+		if handlers.cancel == nil {
+			return nil, nil
+		}
+		return nil, handlers.cancel(appCtx, interaction)
 	default:
-		return errors.New("unknown interaction type")
+		res := &DialogSuggestions{
+			Options: []slack.DialogSelectOption{},
+		}
+		if handlers.suggest == nil {
+			return res, nil
+		}
+		loaded, err := handlers.suggest(appCtx, interaction)
+		if err != nil || loaded == nil {
+			return res, err
+		}
+		return loaded, nil
 	}
 }
 
@@ -127,12 +280,19 @@ func (me *app) handleShortcut(ctx context.Context, shortcuts map[string]Shortcut
 		Context: ctx,
 		app:     me,
 		msgOpts: messageOptions{
-			TeamID:      interaction.Team.ID,
-			ResponseURL: interaction.ResponseURL,
+			TeamID:       interaction.Team.ID,
+			EnterpriseID: interaction.Enterprise.ID,
+			ChannelID:    interaction.Channel.ID, // only for message shortcuts
+			ResponseURL:  interaction.ResponseURL,
 		},
 		source: SourceInfo{
-			TeamID: interaction.Team.ID,
-			UserID: interaction.User.ID,
+			TeamID:       interaction.Team.ID,
+			EnterpriseID: interaction.Enterprise.ID,
+			UserID:       interaction.User.ID,
+			ChannelID:    interaction.Channel.ID,
+			MessageTS:    interaction.Message.Timestamp,
+			ThreadTS:     interaction.Message.ThreadTimestamp,
+			TriggerID:    interaction.TriggerID,
 		},
 	}
 
@@ -151,23 +311,60 @@ func (me *app) handleShortcut(ctx context.Context, shortcuts map[string]Shortcut
 }
 
 func (me *app) handleBlockActions(ctx context.Context, interaction slack.InteractionCallback) error {
-	meta, err := deserializeMetadata(&interaction.Message.Metadata, interaction.View.PrivateMetadata)
+	meta, err := me.decodeMetadata(ctx, &interaction.Message.Metadata, interaction.View.PrivateMetadata)
 	if err != nil {
 		return err
 	}
 
 	src := SourceInfo{
-		TeamID: interaction.Team.ID,
-		UserID: interaction.User.ID,
+		TeamID:       interaction.Team.ID,
+		EnterpriseID: interaction.Enterprise.ID,
+		UserID:       interaction.User.ID,
+		ChannelID:    interaction.Channel.ID,
+		TriggerID:    interaction.TriggerID,
+	}
+
+	var view *slack.View
+	if interaction.View.Type == slack.VTModal {
+		view = &interaction.View
 	}
 
+	appCtx := &appContext{
+		Context: ctx,
+		app:     me,
+		msgOpts: messageOptions{
+			TeamID:       interaction.Team.ID,
+			EnterpriseID: interaction.Enterprise.ID,
+		},
+		source: src,
+	}
+	ctx = withInteraction(ctx, &interactionData{
+		triggerID: interaction.TriggerID,
+		openModal: func(flow *FlowHandle, props FlowProps) error {
+			msg, err := appCtx.StartFlow(flow, props)
+			if err != nil {
+				return err
+			}
+			if msg == nil {
+				return errors.New("flow cannot be opened as a modal")
+			}
+			// modals opened from another modal must be added to its stack
+			if view != nil {
+				return appCtx.PushModal(msg, "")
+			}
+			return appCtx.OpenModal(msg, "")
+		},
+	})
+
 	return me.multiStageRender(ctx, multiStageOptions{
 		meta:   meta,
 		src:    src,
 		isHome: interaction.View.Type == slack.VTHomeTab,
+		view:   view,
 		msgOpts: messageOptions{
-			TeamID:      interaction.Team.ID,
-			ResponseURL: interaction.ResponseURL,
+			TeamID:       interaction.Team.ID,
+			EnterpriseID: interaction.Enterprise.ID,
+			ResponseURL:  interaction.ResponseURL,
 		},
 		async: asyncStateData{
 			ChannelID: interaction.Channel.ID,
@@ -186,15 +383,50 @@ func (me *app) handleBlockActions(ctx context.Context, interaction slack.Interac
 	})
 }
 
-func (me *app) handleViewSubmission(ctx context.Context, interaction slack.InteractionCallback) error {
-	meta, err := deserializeMetadata(&interaction.Message.Metadata, interaction.View.PrivateMetadata)
+func (me *app) handleBlockSuggestion(ctx context.Context, interaction slack.InteractionCallback) (any, error) {
+	meta, err := me.decodeMetadata(ctx, &interaction.Message.Metadata, interaction.View.PrivateMetadata)
+	if err != nil && interaction.Container.MessageTs != "" {
+		// suggestions don't always carry the message, fetch it instead
+		var msg *slack.Msg
+		msg, err = me.getMessage(ctx, interaction.Team.ID, interaction.Enterprise.ID, interaction.Container.ChannelID, interaction.Container.MessageTs)
+		if err == nil {
+			meta, err = me.decodeMetadata(ctx, &msg.Metadata, "")
+		}
+	}
 	if err != nil {
-		return err
+		return nil, err
+	}
+	me.LogDebugf("using meta: %+v", meta)
+
+	flow, ok := me.flows[FlowHandle{
+		id: meta.Flow,
+	}]
+	if !ok {
+		return nil, errors.New("unknown flow")
+	}
+
+	options, err := flow.loadOptions(ctx, meta, SourceInfo{
+		TeamID:       interaction.Team.ID,
+		EnterpriseID: interaction.Enterprise.ID,
+		UserID:       interaction.User.ID,
+	}, interaction.ActionID, interaction.Value)
+	if err != nil {
+		return nil, err
+	}
+	return &slack.OptionsResponse{
+		Options: options,
+	}, nil
+}
+
+func (me *app) handleViewSubmission(ctx context.Context, interaction slack.InteractionCallback) (any, error) {
+	meta, err := me.decodeMetadata(ctx, &interaction.Message.Metadata, interaction.View.PrivateMetadata)
+	if err != nil {
+		return nil, err
 	}
 
 	handler, found := me.viewSubmitted[meta.Flow]
 	if !found {
-		return errors.New("unknown view submission")
+		return nil, errors.New("unknown view submission")
 	}
 
 	url := interaction.ResponseURL
@@ -205,25 +437,94 @@ func (me *app) handleViewSubmission(ctx context.Context, interaction slack.Inter
 		break
 	}
 
-	return handler(&appContext{
+	res, err := handler(&appContext{
 		Context: ctx,
 		app:     me,
 		msgOpts: messageOptions{
-			TeamID:      interaction.Team.ID,
-			ChannelID:   channelID,
-			ResponseURL: url,
+			TeamID:       interaction.Team.ID,
+			EnterpriseID: interaction.Enterprise.ID,
+			ChannelID:    channelID,
+			ResponseURL:  url,
 		},
 		source: SourceInfo{
-			TeamID: interaction.Team.ID,
-			UserID: interaction.User.ID,
+			TeamID:       interaction.Team.ID,
+			EnterpriseID: interaction.Enterprise.ID,
+			UserID:       interaction.User.ID,
 		},
 	}, interaction)
+	var viewErrs ViewErrors
+	if errors.As(err, &viewErrs) {
+		return ViewResponseErrors(viewErrs).toSlack(ctx, me)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if res == nil {
+		me.releaseMetadata(meta)
+		return nil, nil
+	}
+	slackRes, err := res.toSlack(ctx, me)
+	if err != nil {
+		return nil, err
+	}
+	// the submitted view stays in the stack when errors are displayed or another view is pushed on top of it
+	if res.action != slack.RAErrors && res.action != slack.RAPush {
+		me.releaseMetadata(meta)
+	}
+	return slackRes, nil
+}
+
+func (me *app) handleViewClosed(ctx context.Context, interaction slack.InteractionCallback) error {
+	meta, err := me.decodeMetadata(ctx, &interaction.Message.Metadata, interaction.View.PrivateMetadata)
+	if err != nil {
+		return err
+	}
+
+	src := SourceInfo{
+		TeamID:       interaction.Team.ID,
+		EnterpriseID: interaction.Enterprise.ID,
+		UserID:       interaction.User.ID,
+	}
+
+	handler, hasHandler := me.viewClosed[meta.Flow]
+	flow, hasFlow := me.flows[FlowHandle{
+		id: meta.Flow,
+	}]
+	if !hasHandler && !hasFlow {
+		return errors.New("unknown view closed")
+	}
+
+	if hasHandler {
+		err = handler(&appContext{
+			Context: ctx,
+			app:     me,
+			msgOpts: messageOptions{
+				TeamID:       interaction.Team.ID,
+				EnterpriseID: interaction.Enterprise.ID,
+			},
+			source: src,
+		}, interaction)
+		if err != nil {
+			return err
+		}
+	}
+
+	if hasFlow {
+		err = flow.close(ctx, meta, src, interaction.View)
+		if err != nil {
+			return err
+		}
+	}
+	me.releaseMetadata(meta)
+	return nil
 }
 
 type multiStageOptions struct {
-	meta          *slackMetadataJet
-	src           SourceInfo
-	isHome        bool
+	meta   *slackMetadataJet
+	src    SourceInfo
+	isHome bool
+	// when the flow is rendered in a modal
+	view          *slack.View
 	msgOpts       messageOptions
 	async         asyncStateData
 	betweenStages func(rctx *renderContext) error
@@ -240,35 +541,49 @@ func (me *app) multiStageRender(ctx context.Context, opts multiStageOptions) err
 	}
 
 	msg, err := flow.multiStageRender(ctx, opts.meta, opts.src, &asyncStateData{
-		TeamID:      opts.src.TeamID,
-		UserID:      opts.src.UserID,
-		IsHome:      opts.isHome,
-		ChannelID:   opts.async.ChannelID,
-		MessageTS:   opts.async.MessageTS,
-		ResponseURL: opts.async.ResponseURL,
-		Metadata:    opts.async.Metadata,
+		TeamID:       opts.src.TeamID,
+		EnterpriseID: opts.src.EnterpriseID,
+		UserID:       opts.src.UserID,
+		IsHome:       opts.isHome,
+		ChannelID:    opts.async.ChannelID,
+		MessageTS:    opts.async.MessageTS,
+		ResponseURL:  opts.async.ResponseURL,
+		Metadata:     opts.async.Metadata,
 	}, opts.betweenStages)
 	if err != nil {
 		return err
 	}
 
 	if opts.isHome {
-		return me.publishView(ctx, &msg.Msg, messageOptions{
-			TeamID: opts.src.TeamID,
-			UserID: opts.src.UserID,
+		err = me.publishView(ctx, &msg.Msg, messageOptions{
+			TeamID:       opts.src.TeamID,
+			EnterpriseID: opts.src.EnterpriseID,
+			UserID:       opts.src.UserID,
 		})
+	} else if opts.view != nil {
+		modalCfg := modalConfigFromView(*opts.view)
+		if msg.modal != nil {
+			modalCfg = *msg.modal
+		}
+		err = me.updateView(ctx, &msg.Msg, modalCfg, opts.view.ID, opts.view.Hash, opts.msgOpts)
+	} else {
+		err = me.updateMessage(ctx, &msg.Msg, opts.msgOpts)
 	}
-	return me.updateMessage(ctx, &msg.Msg, opts.msgOpts)
+	if err != nil {
+		return err
+	}
+	me.releaseMetadata(opts.meta)
+	return nil
 }
 
 func (me *app) handleAsyncData(ctx context.Context, data asyncStateData, value json.RawMessage) error {
 	me.LogDebugf("handling async data: %+v", data)
 
 	var meta *slackMetadataJet
-	msg, err := me.getMessage(ctx, data.TeamID, data.ChannelID, data.MessageTS)
+	msg, err := me.getMessage(ctx, data.TeamID, data.EnterpriseID, data.ChannelID, data.MessageTS)
 	if err != nil {
 		if data.Metadata != nil {
-			meta, err = deserializeMetadata(data.Metadata, "")
+			meta, err = me.decodeMetadata(ctx, data.Metadata, "")
 			if err != nil {
 				return err
 			}
@@ -276,7 +591,7 @@ func (me *app) handleAsyncData(ctx context.Context, data asyncStateData, value j
 			return err
 		}
 	} else {
-		meta, err = deserializeMetadata(&msg.Metadata, "")
+		meta, err = me.decodeMetadata(ctx, &msg.Metadata, "")
 		if err != nil {
 			return err
 		}
@@ -285,15 +600,17 @@ func (me *app) handleAsyncData(ctx context.Context, data asyncStateData, value j
 	return me.multiStageRender(ctx, multiStageOptions{
 		meta: meta,
 		src: SourceInfo{
-			TeamID: data.TeamID,
-			UserID: data.UserID,
+			TeamID:       data.TeamID,
+			EnterpriseID: data.EnterpriseID,
+			UserID:       data.UserID,
 		},
 		isHome: data.IsHome,
 		msgOpts: messageOptions{
-			TeamID:      data.TeamID,
-			ChannelID:   data.ChannelID,
-			MessageTS:   data.MessageTS,
-			ResponseURL: data.ResponseURL,
+			TeamID:       data.TeamID,
+			EnterpriseID: data.EnterpriseID,
+			ChannelID:    data.ChannelID,
+			MessageTS:    data.MessageTS,
+			ResponseURL:  data.ResponseURL,
 		},
 		async: data,
 		betweenStages: func(rctx *renderContext) error {
@@ -302,52 +619,72 @@ func (me *app) handleAsyncData(ctx context.Context, data asyncStateData, value j
 	})
 }
 
-func (me *app) FinalizeOAuth(ctx context.Context, code, state string) http.Handler {
-	cfg := me.opts.OAuthConfig
-
-	if cfg == nil {
-		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			w.WriteHeader(http.StatusNotImplemented)
-			w.Write([]byte("OAuth is not configured correctly, please provide `OAuthConfig` to `jet.NewBuilder().Build()`"))
-		})
-	}
-
-	if code == "" {
-		err := fmt.Errorf("missing code in oauth request (slack issue)")
-		me.LogErrorf("failed to handle oauth: %+v", err)
-		return cfg.RenderErrorPage(err)
+func (me *app) HandleEvent(ctx context.Context, event slackevents.EventsAPIEvent) (any, error) {
+	me.LogDebugf("handling event: %+v", event)
+	switch event.Type {
+	case slackevents.URLVerification:
+		verification, ok := event.Data.(*slackevents.EventsAPIURLVerificationEvent)
+		if !ok {
+			return nil, errors.New("invalid url verification event")
+		}
+		return &EventChallengeResponse{
+			Challenge: verification.Challenge,
+		}, nil
+	case slackevents.CallbackEvent:
+		return nil, me.handleCallbackEvent(ctx, event)
+	case slackevents.AppRateLimited:
+		me.LogErrorf("events are being rate limited by Slack: %+v", event.Data)
+		return nil, nil
+	default:
+		return nil, errors.New("unknown event type")
 	}
+}
 
-	resp, err := me.tokenExchange(ctx, code, cfg.ClientID, cfg.ClientSecret)
-	if err != nil {
-		me.LogErrorf("failed to exchange code for token: %+v", err)
-		return cfg.RenderErrorPage(err)
+func (me *app) handleCallbackEvent(ctx context.Context, event slackevents.EventsAPIEvent) error {
+	if event.InnerEvent.Type == string(slackevents.FunctionExecuted) {
+		handled, err := me.handleFunctionExecuted(ctx, event)
+		if handled || err != nil {
+			return err
+		}
 	}
 
-	err = cfg.OnSuccess(OAuthSuccessData{
-		TeamID:      resp.Team.ID,
-		AccessToken: resp.AccessToken,
-		State:       state,
-	})
-	if err != nil {
-		me.LogErrorf("failed to handle oauth: %+v", err)
-		return cfg.RenderErrorPage(err)
+	handler, found := me.events[event.InnerEvent.Type]
+	if !found {
+		me.LogDebugf("no handler for event: %s", event.InnerEvent.Type)
+		return nil
 	}
 
-	return cfg.RenderSuccessPage
+	userID, channelID := parseEventLocation(event)
+	return handler(&appContext{
+		Context: ctx,
+		app:     me,
+		msgOpts: messageOptions{
+			TeamID:       event.TeamID,
+			EnterpriseID: event.EnterpriseID,
+			ChannelID:    channelID,
+		},
+		source: SourceInfo{
+			TeamID:       event.TeamID,
+			EnterpriseID: event.EnterpriseID,
+			UserID:       userID,
+			ChannelID:    channelID,
+		},
+	}, event)
 }
 
-func (me *app) UpdateHome(ctx context.Context, workspaceID, userID string, updater HomeUpdater) error {
+func (me *app) UpdateHome(ctx context.Context, teamID, enterpriseID, userID string, updater HomeUpdater) error {
 	appCtx := &appContext{
 		Context: ctx,
 		app:     me,
 		msgOpts: messageOptions{
-			TeamID: workspaceID,
-			UserID: userID,
+			TeamID:       teamID,
+			EnterpriseID: enterpriseID,
+			UserID:       userID,
 		},
 		source: SourceInfo{
-			TeamID: workspaceID,
-			UserID: userID,
+			TeamID:       teamID,
+			EnterpriseID: enterpriseID,
+			UserID:       userID,
 		},
 		isHome: true,
 	}
@@ -363,6 +700,6 @@ func (me *app) UpdateHome(ctx context.Context, workspaceID, userID string, updat
 	return me.publishView(ctx, &msg.Msg, appCtx.msgOpts)
 }
 
-func (me *app) SlackAPI(teamID string) (*slack.Client, error) {
-	return me.makeClientFor(teamID)
+func (me *app) SlackAPI(teamID, enterpriseID string) (*slack.Client, error) {
+	return me.makeClientFor(context.Background(), teamID, enterpriseID)
 }
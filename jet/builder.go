@@ -2,6 +2,8 @@ package jet
 
 import (
 	"fmt"
+
+	"github.com/slack-go/slack/slackevents"
 )
 
 var ErrDuplicateFlowHandle = fmt.Errorf("duplicate flow name")
@@ -14,27 +16,52 @@ type AppBuilder interface {
 	AddMessageShortcut(name string, handler ShortcutHandler) AppBuilder
 	HandleUnknownShortcut(handler ShortcutHandler) AppBuilder
 	HandleSubmittedView(name string, handler ViewSubmittedHandler) AppBuilder
+	HandleClosedView(name string, handler ViewClosedHandler) AppBuilder
+	// handles a legacy dialog opened with `Context.OpenDialog`, any handler can be nil
+	HandleDialog(callbackID string, submit DialogSubmitHandler, cancel DialogCancelHandler, suggest DialogSuggestionHandler) AppBuilder
+	// handles the legacy attachment buttons and menus with the given callback ID
+	HandleInteractiveMessage(callbackID string, handler InteractiveMessageHandler) AppBuilder
+	HandleUnknownInteraction(handler InteractionHandler) AppBuilder
+	HandleEvent(eventType string, handler EventHandler) AppBuilder
+	HandleAppMention(handler func(ctx Context, event *slackevents.AppMentionEvent) error) AppBuilder
+	HandleMessage(handler func(ctx Context, event *slackevents.MessageEvent) error) AppBuilder
+	HandleReactionAdded(handler func(ctx Context, event *slackevents.ReactionAddedEvent) error) AppBuilder
+	HandleReactionRemoved(handler func(ctx Context, event *slackevents.ReactionRemovedEvent) error) AppBuilder
+	HandleMemberJoinedChannel(handler func(ctx Context, event *slackevents.MemberJoinedChannelEvent) error) AppBuilder
+	// handles the custom step with the given callback ID (from the app manifest) when it runs in Workflow Builder
+	AddWorkflowFunction(callbackID string, handler WorkflowFunctionHandler) AppBuilder
 
 	Build(opts Options) App
 }
 
 type appBuilder struct {
-	flows            map[FlowHandle]*Flow
-	slashes          map[string]SlashCommandHandler
-	unknownSlash     SlashCommandHandler
-	globalShortcuts  map[string]ShortcutHandler
-	messageShortcuts map[string]ShortcutHandler
-	viewSubmitted    map[string]ViewSubmittedHandler
-	unknownShortcut  ShortcutHandler
+	flows               map[FlowHandle]*Flow
+	slashes             map[string]SlashCommandHandler
+	unknownSlash        SlashCommandHandler
+	globalShortcuts     map[string]ShortcutHandler
+	messageShortcuts    map[string]ShortcutHandler
+	viewSubmitted       map[string]ViewSubmittedHandler
+	viewClosed          map[string]ViewClosedHandler
+	unknownShortcut     ShortcutHandler
+	dialogs             map[string]dialogHandlers
+	interactiveMessages map[string]InteractiveMessageHandler
+	unknownInteraction  InteractionHandler
+	events              map[string]EventHandler
+	workflowFunctions   map[string]WorkflowFunctionHandler
 }
 
 func NewBuilder() AppBuilder {
 	return &appBuilder{
-		flows:            make(map[FlowHandle]*Flow),
-		slashes:          make(map[string]SlashCommandHandler),
-		globalShortcuts:  make(map[string]ShortcutHandler),
-		messageShortcuts: make(map[string]ShortcutHandler),
-		viewSubmitted:    make(map[string]ViewSubmittedHandler),
+		flows:               make(map[FlowHandle]*Flow),
+		slashes:             make(map[string]SlashCommandHandler),
+		globalShortcuts:     make(map[string]ShortcutHandler),
+		messageShortcuts:    make(map[string]ShortcutHandler),
+		viewSubmitted:       make(map[string]ViewSubmittedHandler),
+		viewClosed:          make(map[string]ViewClosedHandler),
+		dialogs:             make(map[string]dialogHandlers),
+		interactiveMessages: make(map[string]InteractiveMessageHandler),
+		events:              make(map[string]EventHandler),
+		workflowFunctions:   make(map[string]WorkflowFunctionHandler),
 	}
 }
 
@@ -80,15 +107,76 @@ func (me *appBuilder) HandleSubmittedView(name string, handler ViewSubmittedHand
 	return me
 }
 
+func (me *appBuilder) HandleClosedView(name string, handler ViewClosedHandler) AppBuilder {
+	me.viewClosed[name] = handler
+	return me
+}
+
+func (me *appBuilder) HandleDialog(callbackID string, submit DialogSubmitHandler, cancel DialogCancelHandler, suggest DialogSuggestionHandler) AppBuilder {
+	me.dialogs[callbackID] = dialogHandlers{
+		submit:  submit,
+		cancel:  cancel,
+		suggest: suggest,
+	}
+	return me
+}
+
+func (me *appBuilder) HandleInteractiveMessage(callbackID string, handler InteractiveMessageHandler) AppBuilder {
+	me.interactiveMessages[callbackID] = handler
+	return me
+}
+
+func (me *appBuilder) HandleUnknownInteraction(handler InteractionHandler) AppBuilder {
+	me.unknownInteraction = handler
+	return me
+}
+
+func (me *appBuilder) HandleEvent(eventType string, handler EventHandler) AppBuilder {
+	me.events[eventType] = handler
+	return me
+}
+
+func (me *appBuilder) HandleAppMention(handler func(ctx Context, event *slackevents.AppMentionEvent) error) AppBuilder {
+	return me.HandleEvent(string(slackevents.AppMention), TypedEventHandler(handler))
+}
+
+func (me *appBuilder) HandleMessage(handler func(ctx Context, event *slackevents.MessageEvent) error) AppBuilder {
+	return me.HandleEvent(string(slackevents.Message), TypedEventHandler(handler))
+}
+
+func (me *appBuilder) HandleReactionAdded(handler func(ctx Context, event *slackevents.ReactionAddedEvent) error) AppBuilder {
+	return me.HandleEvent(string(slackevents.ReactionAdded), TypedEventHandler(handler))
+}
+
+func (me *appBuilder) HandleReactionRemoved(handler func(ctx Context, event *slackevents.ReactionRemovedEvent) error) AppBuilder {
+	return me.HandleEvent(string(slackevents.ReactionRemoved), TypedEventHandler(handler))
+}
+
+func (me *appBuilder) HandleMemberJoinedChannel(handler func(ctx Context, event *slackevents.MemberJoinedChannelEvent) error) AppBuilder {
+	return me.HandleEvent(string(slackevents.MemberJoinedChannel), TypedEventHandler(handler))
+}
+
+func (me *appBuilder) AddWorkflowFunction(callbackID string, handler WorkflowFunctionHandler) AppBuilder {
+	me.workflowFunctions[callbackID] = handler
+	return me
+}
+
 func (me *appBuilder) Build(opts Options) App {
 	return &app{
-		flows:            me.flows,
-		slashes:          me.slashes,
-		unknownSlash:     me.unknownSlash,
-		globalShortcuts:  me.globalShortcuts,
-		messageShortcuts: me.messageShortcuts,
-		viewSubmitted:    me.viewSubmitted,
-		unknownShortcut:  me.unknownShortcut,
-		opts:             opts,
+		flows:               me.flows,
+		slashes:             me.slashes,
+		unknownSlash:        me.unknownSlash,
+		globalShortcuts:     me.globalShortcuts,
+		messageShortcuts:    me.messageShortcuts,
+		viewSubmitted:       me.viewSubmitted,
+		viewClosed:          me.viewClosed,
+		unknownShortcut:     me.unknownShortcut,
+		dialogs:             me.dialogs,
+		interactiveMessages: me.interactiveMessages,
+		unknownInteraction:  me.unknownInteraction,
+		events:              me.events,
+		workflowFunctions:   me.workflowFunctions,
+		opts:                opts,
+		oauthStateSecret:    oauthStateSecret(opts.OAuthConfig),
 	}
 }
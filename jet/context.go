@@ -40,11 +40,40 @@ func MarshalProps[T structLike](data T) (FlowProps, error) {
 	return props, err
 }
 
+// DecodeViewState fills the struct pointed by data with the inputs of a submitted view.
+// Fields are matched with a `jet:"block_id,action_id"` tag, the action ID can be omitted if the block only has one input.
+func DecodeViewState[T structLike](interaction slack.InteractionCallback, data T) error {
+	values, err := viewStateValues(interaction.View.State, data)
+	if err != nil {
+		return err
+	}
+	decoder, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+		Result:           data,
+		WeaklyTypedInput: true,
+		DecodeHook:       viewStateTimeHook,
+	})
+	if err != nil {
+		return err
+	}
+	return decoder.Decode(values)
+}
+
 type Context interface {
 	context.Context
 	StartFlow(flow *FlowHandle, props FlowProps) (*Message, error)
 	StartFlowAndPost(flow *FlowHandle, props FlowProps) error
+	// posts the flow as a reply in the thread of the source message (e.g. from a message shortcut)
+	StartFlowInThread(flow *FlowHandle, props FlowProps) error
+	// the trigger ID can be left empty to use the one of the interaction
 	OpenModal(msg *Message, triggerID string) error
+	PushModal(msg *Message, triggerID string) error
+	// hash can be left empty to skip the race condition check
+	UpdateModal(msg *Message, viewID, hash string) error
+	Source() SourceInfo
+	// returns the trigger ID of the interaction (if any), it expires after 3 seconds and can only be used once
+	TriggerID() string
+	// opens a legacy dialog, prefer modals for new features (the trigger ID can be left empty like for modals)
+	OpenDialog(dialog slack.Dialog, triggerID string) error
 	App() App
 }
 
@@ -57,14 +86,19 @@ type appContext struct {
 }
 
 type messageOptions struct {
-	TeamID string
+	TeamID       string
+	EnterpriseID string
 	// when using {post|update}Message (initial messages/background updates)
 	ChannelID string
 	MessageTS string
+	// when replying in a thread
+	ThreadTS string
 	// when using interactivity
 	ResponseURL string
 	// when using home
 	UserID string
+	// overrides the token of the team (e.g. for workflow functions)
+	Token string
 }
 
 func (me *appContext) renderFlow(flow *FlowHandle, props FlowProps) (*Flow, *Message, postCreateFlowFn, error) {
@@ -94,7 +128,7 @@ func (me *appContext) StartFlow(flow *FlowHandle, props FlowProps) (*Message, er
 }
 
 func (me *appContext) createWithPost(f *Flow, msg *Message, post postCreateFlowFn) error {
-	ts, err := me.app.createMessage(me.Context, &msg.Msg, me.msgOpts)
+	ts, sent, err := me.app.createMessage(me.Context, &msg.Msg, me.msgOpts)
 	if err != nil {
 		return err
 	}
@@ -106,13 +140,14 @@ func (me *appContext) createWithPost(f *Flow, msg *Message, post postCreateFlowF
 			responseURL = me.msgOpts.ResponseURL
 		}
 		go func() {
-			err := me.processPostFlow(context.Background(), post, msg, &asyncStateData{
-				TeamID:      me.msgOpts.TeamID,
-				UserID:      me.msgOpts.UserID,
-				ChannelID:   me.msgOpts.ChannelID,
-				MessageTS:   ts,
-				ResponseURL: responseURL,
-				Metadata:    extraMeta,
+			err := me.processPostFlow(context.Background(), post, sent, &asyncStateData{
+				TeamID:       me.msgOpts.TeamID,
+				EnterpriseID: me.msgOpts.EnterpriseID,
+				UserID:       me.msgOpts.UserID,
+				ChannelID:    me.msgOpts.ChannelID,
+				MessageTS:    ts,
+				ResponseURL:  responseURL,
+				Metadata:     extraMeta,
 			})
 			if err != nil {
 				me.app.LogErrorf("failed to process post flow: %v", err)
@@ -122,8 +157,9 @@ func (me *appContext) createWithPost(f *Flow, msg *Message, post postCreateFlowF
 	return nil
 }
 
-func (me *appContext) processPostFlow(ctx context.Context, post postCreateFlowFn, orig *Message, async *asyncStateData) error {
-	meta, err := deserializeMetadata(&orig.Metadata, "")
+// processPostFlow runs the effects of a flow once posted, starting from the metadata which was sent (so its stored state can be released)
+func (me *appContext) processPostFlow(ctx context.Context, post postCreateFlowFn, sent *slack.SlackMetadata, async *asyncStateData) error {
+	meta, err := me.app.decodeMetadata(ctx, sent, "")
 	if err != nil {
 		return err
 	}
@@ -131,21 +167,57 @@ func (me *appContext) processPostFlow(ctx context.Context, post postCreateFlowFn
 	if err != nil {
 		return err
 	}
-	return me.app.updateMessage(ctx, &msg.Msg, messageOptions{
-		TeamID:      async.TeamID,
-		ChannelID:   async.ChannelID,
-		MessageTS:   async.MessageTS,
-		ResponseURL: async.ResponseURL,
+	err = me.app.updateMessage(ctx, &msg.Msg, messageOptions{
+		TeamID:       async.TeamID,
+		EnterpriseID: async.EnterpriseID,
+		ChannelID:    async.ChannelID,
+		MessageTS:    async.MessageTS,
+		ResponseURL:  async.ResponseURL,
 	})
+	if err != nil {
+		return err
+	}
+	me.app.releaseMetadata(meta)
+	return nil
 }
 
 func (me *appContext) OpenModal(msg *Message, triggerID string) error {
 	if msg.modal == nil {
 		return errors.New("message is not a modal")
 	}
+	triggerID, err := me.triggerIDOr(triggerID)
+	if err != nil {
+		return err
+	}
 	return me.app.openView(me.Context, &msg.Msg, *msg.modal, triggerID, me.msgOpts)
 }
 
+func (me *appContext) PushModal(msg *Message, triggerID string) error {
+	if msg.modal == nil {
+		return errors.New("message is not a modal")
+	}
+	triggerID, err := me.triggerIDOr(triggerID)
+	if err != nil {
+		return err
+	}
+	return me.app.pushView(me.Context, &msg.Msg, *msg.modal, triggerID, me.msgOpts)
+}
+
+func (me *appContext) UpdateModal(msg *Message, viewID, hash string) error {
+	if msg.modal == nil {
+		return errors.New("message is not a modal")
+	}
+	return me.app.updateView(me.Context, &msg.Msg, *msg.modal, viewID, hash, me.msgOpts)
+}
+
+func (me *appContext) OpenDialog(dialog slack.Dialog, triggerID string) error {
+	triggerID, err := me.triggerIDOr(triggerID)
+	if err != nil {
+		return err
+	}
+	return me.app.openDialog(me.Context, dialog, triggerID, me.msgOpts)
+}
+
 func (me *appContext) StartFlowAndPost(flow *FlowHandle, props FlowProps) error {
 	f, msg, post, err := me.renderFlow(flow, props)
 	if err != nil {
@@ -154,6 +226,39 @@ func (me *appContext) StartFlowAndPost(flow *FlowHandle, props FlowProps) error
 	return me.createWithPost(f, msg, post)
 }
 
+func (me *appContext) StartFlowInThread(flow *FlowHandle, props FlowProps) error {
+	if me.source.ChannelID == "" || me.source.MessageTS == "" {
+		return errors.New("missing source message when using StartFlowInThread")
+	}
+	threadCtx := *me
+	threadCtx.msgOpts.ChannelID = me.source.ChannelID
+	threadCtx.msgOpts.ThreadTS = me.source.ThreadTS
+	if threadCtx.msgOpts.ThreadTS == "" {
+		threadCtx.msgOpts.ThreadTS = me.source.MessageTS
+	}
+	// response URLs cannot post in threads
+	threadCtx.msgOpts.ResponseURL = ""
+	return threadCtx.StartFlowAndPost(flow, props)
+}
+
+func (me *appContext) Source() SourceInfo {
+	return me.source
+}
+
+func (me *appContext) TriggerID() string {
+	return me.source.TriggerID
+}
+
+func (me *appContext) triggerIDOr(triggerID string) (string, error) {
+	if triggerID != "" {
+		return triggerID, nil
+	}
+	if me.source.TriggerID == "" {
+		return "", errors.New("missing trigger ID, the interaction doesn't provide one")
+	}
+	return me.source.TriggerID, nil
+}
+
 func (me *appContext) App() App {
 	return me.app
 }
@@ -173,3 +278,11 @@ func StartFlowAndPost[T structLike](ctx Context, flow *FlowHandle, props T) erro
 	}
 	return ctx.StartFlowAndPost(flow, propsMap)
 }
+
+func StartFlowInThread[T structLike](ctx Context, flow *FlowHandle, props T) error {
+	propsMap, err := MarshalProps(props)
+	if err != nil {
+		return err
+	}
+	return ctx.StartFlowInThread(flow, propsMap)
+}
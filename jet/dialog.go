@@ -0,0 +1,71 @@
+package jet
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/slack-go/slack"
+)
+
+// DialogSubmitHandler handles a legacy dialog submission, the values are in `args.Submission`.
+// Returning DialogErrors keeps the dialog open and displays them.
+type DialogSubmitHandler func(ctx Context, args slack.InteractionCallback) error
+
+type DialogCancelHandler func(ctx Context, args slack.InteractionCallback) error
+
+// DialogSuggestionHandler loads the options of an external select in a legacy dialog, what the user typed is in `args.Value`.
+type DialogSuggestionHandler func(ctx Context, args slack.InteractionCallback) (*DialogSuggestions, error)
+
+// DialogSuggestions is the response to a dialog suggestion request, only one of the fields should be used.
+type DialogSuggestions struct {
+	Options      []slack.DialogSelectOption `json:"options"`
+	OptionGroups []slack.DialogOptionGroup  `json:"option_groups,omitempty"`
+}
+
+type dialogHandlers struct {
+	submit  DialogSubmitHandler
+	cancel  DialogCancelHandler
+	suggest DialogSuggestionHandler
+}
+
+// DialogErrors are validation messages keyed by element name, displayed under the matching element of a submitted dialog.
+type DialogErrors map[string]string
+
+func (me DialogErrors) Error() string {
+	names := make([]string, 0, len(me))
+	for name, msg := range me {
+		names = append(names, fmt.Sprintf("%s: %s", name, msg))
+	}
+	sort.Strings(names)
+	return fmt.Sprintf("invalid dialog submission (%s)", strings.Join(names, ", "))
+}
+
+func (me DialogErrors) toSlack() *slack.DialogInputValidationErrors {
+	res := &slack.DialogInputValidationErrors{
+		Errors: make([]slack.DialogInputValidationError, 0, len(me)),
+	}
+	for name, msg := range me {
+		res.Errors = append(res.Errors, slack.DialogInputValidationError{
+			Name:  name,
+			Error: msg,
+		})
+	}
+	sort.Slice(res.Errors, func(i, j int) bool {
+		return res.Errors[i].Name < res.Errors[j].Name
+	})
+	return res
+}
+
+func (me *app) openDialog(ctx context.Context, dialog slack.Dialog, triggerID string, in messageOptions) error {
+	client, err := me.clientFor(ctx, in)
+	if err != nil {
+		return err
+	}
+
+	me.LogDebugf("opening dialog: %+v", dialog)
+	return me.withRetry(ctx, in.TeamID, "dialog.open", false, func() error {
+		return client.OpenDialogContext(ctx, triggerID, dialog)
+	})
+}
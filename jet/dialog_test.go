@@ -0,0 +1,95 @@
+package jet_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/LouisBrunner/jet/jet"
+	"github.com/LouisBrunner/jet/jet/jettest"
+	"github.com/slack-go/slack"
+)
+
+func TestOpenDialogUsesTheTriggerOfTheContext(t *testing.T) {
+	srv := jettest.NewServer()
+	defer srv.Close()
+
+	app := jet.NewBuilder().AddSlash("/legacy", func(ctx jet.Context, args slack.SlashCommand) (*jet.Message, error) {
+		return nil, ctx.OpenDialog(slack.Dialog{
+			CallbackID: "legacy",
+			Title:      "Legacy",
+		}, "")
+	}).Build(srv.Options(jet.Options{}))
+
+	res, err := srv.SlashCommand(context.Background(), app, "/legacy", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res != nil {
+		t.Fatalf("unexpected response: %+v", res.Msg)
+	}
+	dialogs := srv.Dialogs()
+	if len(dialogs) != 1 || dialogs[0].CallbackID != "legacy" || dialogs[0].TriggerID == "" {
+		t.Fatalf("unexpected dialogs: %+v", dialogs)
+	}
+}
+
+func TestHandleDialog(t *testing.T) {
+	app := jet.NewBuilder().HandleDialog("legacy", func(ctx jet.Context, args slack.InteractionCallback) error {
+		if args.Submission["name"] == "" {
+			return jet.DialogErrors{"name": "required"}
+		}
+		return nil
+	}, nil, func(ctx jet.Context, args slack.InteractionCallback) (*jet.DialogSuggestions, error) {
+		return &jet.DialogSuggestions{
+			Options: []slack.DialogSelectOption{{Label: args.Value, Value: "1"}},
+		}, nil
+	}).Build(jet.Options{})
+
+	tests := []struct {
+		name     string
+		payload  string
+		expected string
+	}{
+		{
+			name:     "invalid submission",
+			payload:  `{"type":"dialog_submission","callback_id":"legacy","submission":{"name":""}}`,
+			expected: `{"errors":[{"name":"name","error":"required"}]}`,
+		},
+		{
+			name:     "valid submission",
+			payload:  `{"type":"dialog_submission","callback_id":"legacy","submission":{"name":"jet"}}`,
+			expected: `null`,
+		},
+		{
+			name:     "cancellation without handler",
+			payload:  `{"type":"dialog_cancellation","callback_id":"legacy"}`,
+			expected: `null`,
+		},
+		{
+			name:     "suggestion",
+			payload:  `{"type":"dialog_suggestion","callback_id":"legacy","value":"je"}`,
+			expected: `{"options":[{"label":"je","value":"1"}]}`,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var interaction slack.InteractionCallback
+			err := json.Unmarshal([]byte(test.payload), &interaction)
+			if err != nil {
+				t.Fatal(err)
+			}
+			res, err := app.HandleInteraction(context.Background(), interaction)
+			if err != nil {
+				t.Fatal(err)
+			}
+			raw, err := json.Marshal(res)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(raw) != test.expected {
+				t.Fatalf("expected %s, got %s", test.expected, raw)
+			}
+		})
+	}
+}
@@ -0,0 +1,86 @@
+package jet_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/LouisBrunner/jet/jet"
+	"github.com/LouisBrunner/jet/jet/jettest"
+	"github.com/slack-go/slack"
+)
+
+func TestOrgWideInstallation(t *testing.T) {
+	srv := jettest.NewServer()
+	defer srv.Close()
+
+	ctx := context.Background()
+	store := jet.NewMemoryInstallationStore()
+	err := store.Save(ctx, jet.Installation{EnterpriseID: "E1", IsEnterpriseInstall: true, BotToken: "xoxb-org"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var tokens []string
+	builder := jet.NewBuilder()
+	home, err := builder.AddFlow(jet.NewFlow("home", func(ctx jet.RenderContext, props jet.FlowProps) (*jet.RenderedFlow, error) {
+		return &jet.RenderedFlow{
+			Blocks: slack.Blocks{BlockSet: []slack.Block{
+				slack.NewSectionBlock(plainText("Welcome "+ctx.Source().EnterpriseID), nil, nil),
+			}},
+		}, nil
+	}, nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	app := builder.Build(srv.Options(jet.Options{
+		InstallationStore: store,
+		ClientFactory: func(teamID, token string) *slack.Client {
+			tokens = append(tokens, token)
+			return slack.New(token, slack.OptionAPIURL(srv.APIURL()))
+		},
+	}))
+	updater := func(ctx jet.Context) (*jet.Message, error) {
+		return ctx.StartFlow(home, nil)
+	}
+
+	t.Run("home", func(t *testing.T) {
+		err := app.UpdateHome(ctx, "T1", "", "U1", updater)
+		if !errors.Is(err, jet.ErrInstallationNotFound) {
+			t.Fatalf("expected the workspace to have no installation, got %v", err)
+		}
+
+		err = app.UpdateHome(ctx, "T1", "E1", "U1", updater)
+		if err != nil {
+			t.Fatal(err)
+		}
+		view := srv.HomeView("U1")
+		if view == nil {
+			t.Fatal("expected the home tab to be published")
+		}
+		section, ok := view.Blocks.BlockSet[0].(*slack.SectionBlock)
+		if !ok || section.Text.Text != "Welcome E1" {
+			t.Errorf("expected the flow to see the enterprise, got %+v", view.Blocks.BlockSet[0])
+		}
+	})
+
+	t.Run("api", func(t *testing.T) {
+		_, err := app.SlackAPI("T1", "")
+		if !errors.Is(err, jet.ErrInstallationNotFound) {
+			t.Fatalf("expected the workspace to have no installation, got %v", err)
+		}
+		_, err = app.SlackAPI("T1", "E1")
+		if err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	for _, token := range tokens {
+		if token != "xoxb-org" {
+			t.Errorf("expected the token of the organization, got %q", token)
+		}
+	}
+	if len(tokens) == 0 {
+		t.Error("expected a client to be created")
+	}
+}
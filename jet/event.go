@@ -0,0 +1,61 @@
+package jet
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/slack-go/slack/slackevents"
+)
+
+type EventHandler func(ctx Context, event slackevents.EventsAPIEvent) error
+
+// TypedEventHandler wraps a handler which expects a specific inner event type (e.g. `*slackevents.ReactionAddedEvent`).
+func TypedEventHandler[T any](handler func(ctx Context, event T) error) EventHandler {
+	return func(ctx Context, event slackevents.EventsAPIEvent) error {
+		data, ok := event.InnerEvent.Data.(T)
+		if !ok {
+			return fmt.Errorf("unexpected data for event %q: %T", event.InnerEvent.Type, event.InnerEvent.Data)
+		}
+		return handler(ctx, data)
+	}
+}
+
+// EventChallengeResponse is the body Slack expects when verifying the Events API request URL.
+type EventChallengeResponse struct {
+	Challenge string `json:"challenge"`
+}
+
+// eventLocation contains the fields shared by most inner events, which are used to find where the event happened.
+type eventLocation struct {
+	User    json.RawMessage `json:"user"`
+	Channel json.RawMessage `json:"channel"`
+	Item    struct {
+		Channel string `json:"channel"`
+	} `json:"item"`
+}
+
+func parseEventLocation(event slackevents.EventsAPIEvent) (userID, channelID string) {
+	cb, ok := event.Data.(*slackevents.EventsAPICallbackEvent)
+	if !ok || cb.InnerEvent == nil {
+		return "", ""
+	}
+	var loc eventLocation
+	err := json.Unmarshal(*cb.InnerEvent, &loc)
+	if err != nil {
+		return "", ""
+	}
+	channelID = rawString(loc.Channel)
+	if channelID == "" {
+		channelID = loc.Item.Channel
+	}
+	return rawString(loc.User), channelID
+}
+
+// rawString returns the value if it is a JSON string, some events use objects for `user` and `channel` instead
+func rawString(raw json.RawMessage) string {
+	var value string
+	if json.Unmarshal(raw, &value) != nil {
+		return ""
+	}
+	return value
+}
@@ -0,0 +1,84 @@
+package jet_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/LouisBrunner/jet/jet"
+	"github.com/LouisBrunner/jet/jet/jettest"
+	"github.com/slack-go/slack/slackevents"
+)
+
+func TestEventHandlers(t *testing.T) {
+	srv := jettest.NewServer()
+	defer srv.Close()
+
+	builder := jet.NewBuilder()
+	greeting, err := builder.AddFlow(jet.NewFlow("greeting", func(ctx jet.RenderContext, props jet.FlowProps) (*jet.RenderedFlow, error) {
+		return &jet.RenderedFlow{Text: "hello <@" + ctx.Source().UserID + ">"}, nil
+	}, nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var reaction string
+	app := builder.HandleAppMention(func(ctx jet.Context, event *slackevents.AppMentionEvent) error {
+		return ctx.StartFlowAndPost(greeting, nil)
+	}).HandleReactionAdded(func(ctx jet.Context, event *slackevents.ReactionAddedEvent) error {
+		reaction = event.Reaction
+		return nil
+	}).Build(srv.Options(jet.Options{}))
+
+	ctx := context.Background()
+	err = srv.SendEvent(ctx, app, map[string]any{
+		"type":    "app_mention",
+		"user":    "U2",
+		"channel": srv.ChannelID,
+		"text":    "<@UBOT> hi",
+		"ts":      "1.000001",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg := srv.LastMessage(srv.ChannelID)
+	if msg == nil || msg.Text != "hello <@U2>" {
+		t.Errorf("expected a greeting in the channel of the mention, got %+v", msg)
+	}
+
+	err = srv.SendEvent(ctx, app, map[string]any{
+		"type":     "reaction_added",
+		"user":     "U2",
+		"reaction": "tada",
+		"item":     map[string]any{"type": "message", "channel": srv.ChannelID, "ts": "1.000001"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reaction != "tada" {
+		t.Errorf("expected the reaction handler to be called, got %q", reaction)
+	}
+
+	// events without a handler are ignored
+	err = srv.SendEvent(ctx, app, map[string]any{
+		"type":    "member_joined_channel",
+		"user":    "U2",
+		"channel": srv.ChannelID,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestEventURLVerification(t *testing.T) {
+	app := jet.NewBuilder().Build(jet.Options{})
+	res, err := app.HandleEvent(context.Background(), slackevents.EventsAPIEvent{
+		Type: slackevents.URLVerification,
+		Data: &slackevents.EventsAPIURLVerificationEvent{Type: slackevents.URLVerification, Challenge: "challenge"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	challenge, ok := res.(*jet.EventChallengeResponse)
+	if !ok || challenge.Challenge != "challenge" {
+		t.Errorf("expected the challenge to be sent back, got %+v", res)
+	}
+}
@@ -15,11 +15,14 @@ type FlowRenderer func(ctx RenderContext, props FlowProps) (*RenderedFlow, error
 
 type FlowOptions struct {
 	CanUpdateWithoutInteraction bool
+	// Store the hooks and props in a compact (and possibly compressed) form, useful for flows which are close to the metadata limits
+	CompactMetadata bool
 }
 
 type Flow struct {
 	name                           string
 	canUpdateWithoutInteractionOpt bool
+	compactMetadata                bool
 	renderFn                       FlowRenderer
 }
 
@@ -31,6 +34,16 @@ type ModalConfig struct {
 	NotifyOnClose bool
 }
 
+func modalConfigFromView(view slack.View) ModalConfig {
+	return ModalConfig{
+		Title:         view.Title,
+		Submit:        view.Submit,
+		Close:         view.Close,
+		ClearOnClose:  view.ClearOnClose,
+		NotifyOnClose: view.NotifyOnClose,
+	}
+}
+
 type RenderedFlow struct {
 	Blocks   slack.Blocks
 	Text     string
@@ -69,6 +82,7 @@ func NewFlow(name string, render FlowRenderer, opts *FlowOptions) Flow {
 	return Flow{
 		name:                           name,
 		canUpdateWithoutInteractionOpt: opt.CanUpdateWithoutInteraction,
+		compactMetadata:                opt.CompactMetadata,
 		renderFn:                       render,
 	}
 }
@@ -105,14 +119,22 @@ func (me *Flow) renderFresh(ctx context.Context, props FlowProps, source SourceI
 	return msg, post, nil
 }
 
-func (me *Flow) multiStageRender(ctx context.Context, meta *slackMetadataJet, src SourceInfo, async *asyncStateData, betweenStages func(rctx *renderContext) error) (*Message, error) {
+// hydrate renders the flow once to populate the render context with the hooks stored in the metadata
+func (me *Flow) hydrate(ctx context.Context, meta *slackMetadataJet, src SourceInfo, async *asyncStateData) (*renderContext, error) {
 	rctx, err := newRenderContext(ctx, me.name, nil, meta, src, async)
 	if err != nil {
 		return nil, err
 	}
+	_, err = me.renderBlocks(rctx)
+	if err != nil {
+		return nil, err
+	}
+	return rctx, nil
+}
 
+func (me *Flow) multiStageRender(ctx context.Context, meta *slackMetadataJet, src SourceInfo, async *asyncStateData, betweenStages func(rctx *renderContext) error) (*Message, error) {
 	// first, we populate the render context
-	_, err = me.renderBlocks(rctx)
+	rctx, err := me.hydrate(ctx, meta, src, async)
 	if err != nil {
 		return nil, err
 	}
@@ -127,11 +149,31 @@ func (me *Flow) multiStageRender(ctx context.Context, meta *slackMetadataJet, sr
 	return me.renderWith(rctx, meta)
 }
 
+func (me *Flow) loadOptions(ctx context.Context, meta *slackMetadataJet, src SourceInfo, actionID, query string) ([]*slack.OptionBlockObject, error) {
+	rctx, err := me.hydrate(ctx, meta, src, nil)
+	if err != nil {
+		return nil, err
+	}
+	return rctx.loadOptions(actionID, query)
+}
+
+func (me *Flow) close(ctx context.Context, meta *slackMetadataJet, src SourceInfo, view slack.View) error {
+	rctx, err := me.hydrate(ctx, meta, src, nil)
+	if err != nil {
+		return err
+	}
+	return rctx.triggerClose(view)
+}
+
 func (me *Flow) renderWith(rctx *renderContext, metadata *slackMetadataJet) (*Message, error) {
 	rendered, err := me.renderBlocks(rctx)
 	if err != nil {
 		return nil, err
 	}
+	return me.buildMessage(rctx, rendered, metadata)
+}
+
+func (me *Flow) buildMessage(rctx *renderContext, rendered *RenderedFlow, metadata *slackMetadataJet) (*Message, error) {
 	var finalMetadata *slack.SlackMetadata
 	if metadata != nil {
 		finalMetadata = &metadata.Original
@@ -150,13 +192,17 @@ func (me *Flow) renderWith(rctx *renderContext, metadata *slackMetadataJet) (*Me
 			}
 		}
 	}
+	serialized, err := serializeMetadata(finalMetadata, me.name, rctx, me.compactMetadata)
+	if err != nil {
+		return nil, err
+	}
 	return &Message{
 		Msg: slack.Msg{
 			ResponseType:    slack.ResponseTypeInChannel,
 			ReplaceOriginal: true,
 			Text:            rendered.Text,
 			Blocks:          rendered.Blocks,
-			Metadata:        serializeMetadata(finalMetadata, me.name, rctx),
+			Metadata:        serialized,
 		},
 		modal: rendered.ForModal,
 	}, nil
@@ -3,6 +3,7 @@ package jet
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 
 	"github.com/slack-go/slack"
@@ -11,8 +12,9 @@ import (
 type UseStateSetter[T any] func(newValue T) error
 
 type asyncStateData struct {
-	TeamID string
-	UserID string
+	TeamID       string
+	EnterpriseID string
+	UserID       string
 
 	IsHome bool
 	// when not home
@@ -118,6 +120,20 @@ func UseCallback(ctx RenderContext, callback Callback) (string, error) {
 	return ctx.addCallback(callback)
 }
 
+type OptionsLoader func(ctx context.Context, query string) ([]*slack.OptionBlockObject, error)
+
+// UseOptionsLoader returns an action ID to use on an external select, the loader is called with what the user typed
+func UseOptionsLoader(ctx RenderContext, loader OptionsLoader) (string, error) {
+	return ctx.addOptionsLoader(loader)
+}
+
+type CloseHandler func(ctx context.Context, view slack.View) error
+
+// UseOnClose registers a handler called when the modal showing the flow is closed (requires `ModalConfig.NotifyOnClose`)
+func UseOnClose(ctx RenderContext, handler CloseHandler) error {
+	return ctx.addCloseHandler(handler)
+}
+
 func ProcessAsyncData[T any](ctx context.Context, app App, async UseStateAsyncData[T], value T) error {
 	valueRaw, err := json.Marshal(value)
 	if err != nil {
@@ -126,6 +142,30 @@ func ProcessAsyncData[T any](ctx context.Context, app App, async UseStateAsyncDa
 	return app.handleAsyncData(ctx, async.P, valueRaw)
 }
 
+// ModalOpener opens another flow as a modal, using the trigger ID of the interaction (the modal is pushed when it comes from another modal).
+type ModalOpener interface {
+	Open(flow *FlowHandle, props FlowProps) error
+}
+
+type modalOpener struct {
+	ctx RenderContext
+}
+
+func (me *modalOpener) Open(flow *FlowHandle, props FlowProps) error {
+	data := interactionFrom(me.ctx)
+	if data == nil || data.openModal == nil {
+		return errors.New("modals can only be opened from a callback triggered by Slack")
+	}
+	return data.openModal(flow, props)
+}
+
+// UseModalOpener returns a ModalOpener which can be used in callbacks
+func UseModalOpener(ctx RenderContext) ModalOpener {
+	return &modalOpener{
+		ctx: ctx,
+	}
+}
+
 type Effect func(ctx context.Context) error
 
 func UseEffectAtStart(ctx RenderContext, effect Effect) error {
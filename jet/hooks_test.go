@@ -0,0 +1,67 @@
+package jet_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/LouisBrunner/jet/jet"
+	"github.com/LouisBrunner/jet/jet/jettest"
+	"github.com/slack-go/slack"
+)
+
+func plainText(text string) *slack.TextBlockObject {
+	return slack.NewTextBlockObject(slack.PlainTextType, text, false, false)
+}
+
+func TestOptionsLoader(t *testing.T) {
+	srv := jettest.NewServer()
+	defer srv.Close()
+
+	fruits := []string{"apple", "apricot", "banana"}
+	builder := jet.NewBuilder()
+	picker, err := builder.AddFlow(jet.NewFlow("picker", func(ctx jet.RenderContext, props jet.FlowProps) (*jet.RenderedFlow, error) {
+		loader, err := jet.UseOptionsLoader(ctx, func(ctx context.Context, query string) ([]*slack.OptionBlockObject, error) {
+			options := []*slack.OptionBlockObject{}
+			for _, fruit := range fruits {
+				if strings.HasPrefix(fruit, query) {
+					options = append(options, slack.NewOptionBlockObject(fruit, plainText(fruit), nil))
+				}
+			}
+			return options, nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		return &jet.RenderedFlow{
+			Text: "pick a fruit",
+			Blocks: slack.Blocks{BlockSet: []slack.Block{
+				slack.NewActionBlock("fruit", slack.NewOptionsSelectBlockElement(slack.OptTypeExternal, plainText("Fruit"), loader)),
+			}},
+		}, nil
+	}, nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	app := builder.AddSlash("/pick", func(ctx jet.Context, args slack.SlashCommand) (*jet.Message, error) {
+		return ctx.StartFlow(picker, nil)
+	}).Build(srv.Options(jet.Options{}))
+
+	ctx := context.Background()
+	msg, err := srv.SlashCommand(ctx, app, "/pick", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := srv.SuggestOptions(ctx, app, msg, "jet_picker_opt_0", "ap")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Options) != 2 || res.Options[0].Value != "apple" || res.Options[1].Value != "apricot" {
+		t.Errorf("expected the matching fruits, got %+v", res.Options)
+	}
+
+	_, err = srv.SuggestOptions(ctx, app, msg, "jet_picker_opt_9", "ap")
+	if err == nil {
+		t.Error("expected unknown loaders to fail")
+	}
+}
@@ -0,0 +1,163 @@
+package jet
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/slack-go/slack"
+)
+
+var ErrInstallationNotFound = errors.New("installation not found")
+
+type IncomingWebhook struct {
+	URL              string `json:"url"`
+	Channel          string `json:"channel"`
+	ChannelID        string `json:"channel_id"`
+	ConfigurationURL string `json:"configuration_url"`
+}
+
+// Installation contains everything Slack returns when the app is installed in a workspace (or organization).
+type Installation struct {
+	AppID string `json:"app_id"`
+
+	TeamID   string `json:"team_id"`
+	TeamName string `json:"team_name"`
+	// only for Enterprise Grid, TeamID is empty for organization-wide installs
+	EnterpriseID        string `json:"enterprise_id,omitempty"`
+	EnterpriseName      string `json:"enterprise_name,omitempty"`
+	IsEnterpriseInstall bool   `json:"is_enterprise_install,omitempty"`
+
+	BotUserID string   `json:"bot_user_id"`
+	BotToken  string   `json:"bot_token"`
+	BotScopes []string `json:"bot_scopes"`
+	// only when token rotation is enabled
+	BotRefreshToken   string    `json:"bot_refresh_token,omitempty"`
+	BotTokenExpiresAt time.Time `json:"bot_token_expires_at,omitzero"`
+
+	// the user who installed the app, the token is only present if user scopes were requested
+	UserID             string    `json:"user_id"`
+	UserToken          string    `json:"user_token,omitempty"`
+	UserScopes         []string  `json:"user_scopes,omitempty"`
+	UserRefreshToken   string    `json:"user_refresh_token,omitempty"`
+	UserTokenExpiresAt time.Time `json:"user_token_expires_at,omitzero"`
+
+	IncomingWebhook *IncomingWebhook `json:"incoming_webhook,omitempty"`
+
+	InstalledAt time.Time `json:"installed_at"`
+}
+
+func newInstallation(resp *slack.OAuthV2Response, now time.Time) Installation {
+	install := Installation{
+		AppID:               resp.AppID,
+		TeamID:              resp.Team.ID,
+		TeamName:            resp.Team.Name,
+		EnterpriseID:        resp.Enterprise.ID,
+		EnterpriseName:      resp.Enterprise.Name,
+		IsEnterpriseInstall: resp.IsEnterpriseInstall,
+		BotUserID:           resp.BotUserID,
+		BotToken:            resp.AccessToken,
+		BotScopes:           splitScopes(resp.Scope),
+		BotRefreshToken:     resp.RefreshToken,
+		UserID:              resp.AuthedUser.ID,
+		UserToken:           resp.AuthedUser.AccessToken,
+		UserScopes:          splitScopes(resp.AuthedUser.Scope),
+		UserRefreshToken:    resp.AuthedUser.RefreshToken,
+		InstalledAt:         now,
+	}
+	if resp.ExpiresIn > 0 {
+		install.BotTokenExpiresAt = now.Add(time.Duration(resp.ExpiresIn) * time.Second)
+	}
+	if resp.AuthedUser.ExpiresIn > 0 {
+		install.UserTokenExpiresAt = now.Add(time.Duration(resp.AuthedUser.ExpiresIn) * time.Second)
+	}
+	if resp.IncomingWebhook.URL != "" {
+		install.IncomingWebhook = &IncomingWebhook{
+			URL:              resp.IncomingWebhook.URL,
+			Channel:          resp.IncomingWebhook.Channel,
+			ChannelID:        resp.IncomingWebhook.ChannelID,
+			ConfigurationURL: resp.IncomingWebhook.ConfigurationURL,
+		}
+	}
+	return install
+}
+
+func splitScopes(scopes string) []string {
+	if scopes == "" {
+		return nil
+	}
+	return strings.Split(scopes, ",")
+}
+
+// installationKey identifies an installation, organization-wide installs are stored by enterprise
+func installationKey(teamID, enterpriseID string) string {
+	if teamID == "" {
+		return "E:" + enterpriseID
+	}
+	return "T:" + teamID
+}
+
+func (me *Installation) key() string {
+	if me.IsEnterpriseInstall {
+		return installationKey("", me.EnterpriseID)
+	}
+	return installationKey(me.TeamID, "")
+}
+
+// InstallationStore keeps the installations (and their tokens) created through OAuth.
+type InstallationStore interface {
+	// replaces any existing installation for the same team (or enterprise for organization-wide installs)
+	Save(ctx context.Context, installation Installation) error
+	// must return ErrInstallationNotFound if the app isn't installed in the team
+	FindByTeam(ctx context.Context, teamID string) (*Installation, error)
+	// must return ErrInstallationNotFound if the app isn't installed organization-wide
+	FindByEnterprise(ctx context.Context, enterpriseID string) (*Installation, error)
+	// teamID is empty for organization-wide installs
+	Delete(ctx context.Context, teamID, enterpriseID string) error
+}
+
+type memoryInstallationStore struct {
+	lock          sync.RWMutex
+	installations map[string]Installation
+}
+
+// NewMemoryInstallationStore creates an InstallationStore which keeps everything in memory, the installations will be lost when the process exits.
+func NewMemoryInstallationStore() InstallationStore {
+	return &memoryInstallationStore{
+		installations: make(map[string]Installation),
+	}
+}
+
+func (me *memoryInstallationStore) Save(ctx context.Context, installation Installation) error {
+	me.lock.Lock()
+	defer me.lock.Unlock()
+	me.installations[installation.key()] = installation
+	return nil
+}
+
+func (me *memoryInstallationStore) find(key string) (*Installation, error) {
+	me.lock.RLock()
+	defer me.lock.RUnlock()
+	installation, found := me.installations[key]
+	if !found {
+		return nil, ErrInstallationNotFound
+	}
+	return &installation, nil
+}
+
+func (me *memoryInstallationStore) FindByTeam(ctx context.Context, teamID string) (*Installation, error) {
+	return me.find(installationKey(teamID, ""))
+}
+
+func (me *memoryInstallationStore) FindByEnterprise(ctx context.Context, enterpriseID string) (*Installation, error) {
+	return me.find(installationKey("", enterpriseID))
+}
+
+func (me *memoryInstallationStore) Delete(ctx context.Context, teamID, enterpriseID string) error {
+	me.lock.Lock()
+	defer me.lock.Unlock()
+	delete(me.installations, installationKey(teamID, enterpriseID))
+	return nil
+}
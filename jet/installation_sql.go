@@ -0,0 +1,122 @@
+package jet
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+)
+
+type SQLInstallationStoreOptions struct {
+	// defaults to `jet_installations`
+	Table string
+	// returns the placeholder for the n-th argument (starting at 1), defaults to `?` (e.g. use `$n` for PostgreSQL)
+	Placeholder func(n int) string
+}
+
+type sqlInstallationStore struct {
+	db          *sql.DB
+	table       string
+	placeholder func(n int) string
+}
+
+var validSQLTable = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_.]*$`)
+
+// NewSQLInstallationStore creates an InstallationStore backed by a SQL database.
+// Each installation is stored as JSON in a single table, which can be created with CreateSQLInstallationTable.
+func NewSQLInstallationStore(db *sql.DB, opts *SQLInstallationStoreOptions) (InstallationStore, error) {
+	opt := SQLInstallationStoreOptions{}
+	if opts != nil {
+		opt = *opts
+	}
+	if opt.Table == "" {
+		opt.Table = "jet_installations"
+	}
+	if !validSQLTable.MatchString(opt.Table) {
+		return nil, fmt.Errorf("invalid table name: %q", opt.Table)
+	}
+	if opt.Placeholder == nil {
+		opt.Placeholder = func(n int) string {
+			return "?"
+		}
+	}
+	return &sqlInstallationStore{
+		db:          db,
+		table:       opt.Table,
+		placeholder: opt.Placeholder,
+	}, nil
+}
+
+// CreateSQLInstallationTable creates the table used by NewSQLInstallationStore if it doesn't exist.
+func CreateSQLInstallationTable(ctx context.Context, db *sql.DB, table string) error {
+	if !validSQLTable.MatchString(table) {
+		return fmt.Errorf("invalid table name: %q", table)
+	}
+	_, err := db.ExecContext(ctx, fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+	installation_key VARCHAR(64) PRIMARY KEY,
+	team_id VARCHAR(32) NOT NULL,
+	enterprise_id VARCHAR(32) NOT NULL,
+	data TEXT NOT NULL
+)`, table))
+	return err
+}
+
+func (me *sqlInstallationStore) Save(ctx context.Context, installation Installation) error {
+	data, err := json.Marshal(installation)
+	if err != nil {
+		return fmt.Errorf("failed to marshal installation: %w", err)
+	}
+	key := installation.key()
+
+	// delete then insert, as upserts are not portable
+	tx, err := me.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	_, err = tx.ExecContext(ctx, fmt.Sprintf("DELETE FROM %s WHERE installation_key = %s", me.table, me.placeholder(1)), key)
+	if err != nil {
+		return fmt.Errorf("failed to delete previous installation: %w", err)
+	}
+	_, err = tx.ExecContext(ctx,
+		fmt.Sprintf("INSERT INTO %s (installation_key, team_id, enterprise_id, data) VALUES (%s, %s, %s, %s)",
+			me.table, me.placeholder(1), me.placeholder(2), me.placeholder(3), me.placeholder(4)),
+		key, installation.TeamID, installation.EnterpriseID, string(data),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to insert installation: %w", err)
+	}
+	return tx.Commit()
+}
+
+func (me *sqlInstallationStore) find(ctx context.Context, key string) (*Installation, error) {
+	var data string
+	err := me.db.QueryRowContext(ctx, fmt.Sprintf("SELECT data FROM %s WHERE installation_key = %s", me.table, me.placeholder(1)), key).Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrInstallationNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find installation: %w", err)
+	}
+	var installation Installation
+	err = json.Unmarshal([]byte(data), &installation)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal installation: %w", err)
+	}
+	return &installation, nil
+}
+
+func (me *sqlInstallationStore) FindByTeam(ctx context.Context, teamID string) (*Installation, error) {
+	return me.find(ctx, installationKey(teamID, ""))
+}
+
+func (me *sqlInstallationStore) FindByEnterprise(ctx context.Context, enterpriseID string) (*Installation, error) {
+	return me.find(ctx, installationKey("", enterpriseID))
+}
+
+func (me *sqlInstallationStore) Delete(ctx context.Context, teamID, enterpriseID string) error {
+	_, err := me.db.ExecContext(ctx, fmt.Sprintf("DELETE FROM %s WHERE installation_key = %s", me.table, me.placeholder(1)), installationKey(teamID, enterpriseID))
+	return err
+}
@@ -0,0 +1,288 @@
+package jet
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func testInstallationStore(t *testing.T, store InstallationStore) {
+	ctx := context.Background()
+	_, err := store.FindByTeam(ctx, "T1")
+	if !errors.Is(err, ErrInstallationNotFound) {
+		t.Fatalf("expected ErrInstallationNotFound, got %v", err)
+	}
+
+	installedAt := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	team := Installation{TeamID: "T1", EnterpriseID: "E1", BotToken: "xoxb-1", BotScopes: []string{"chat:write"}, InstalledAt: installedAt}
+	org := Installation{EnterpriseID: "E1", IsEnterpriseInstall: true, BotToken: "xoxb-org", InstalledAt: installedAt}
+	for _, installation := range []Installation{team, org} {
+		err = store.Save(ctx, installation)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	found, err := store.FindByTeam(ctx, "T1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if found.BotToken != "xoxb-1" || !found.InstalledAt.Equal(installedAt) || len(found.BotScopes) != 1 {
+		t.Errorf("unexpected installation for the team: %+v", found)
+	}
+	found, err = store.FindByEnterprise(ctx, "E1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if found.BotToken != "xoxb-org" {
+		t.Errorf("expected the organization-wide installation, got %+v", found)
+	}
+
+	// saving again replaces the previous installation (e.g. after rotating the tokens)
+	team.BotToken = "xoxb-2"
+	err = store.Save(ctx, team)
+	if err != nil {
+		t.Fatal(err)
+	}
+	found, err = store.FindByTeam(ctx, "T1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if found.BotToken != "xoxb-2" {
+		t.Errorf("expected the installation to be replaced, got %+v", found)
+	}
+
+	err = store.Delete(ctx, "T1", "E1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = store.FindByTeam(ctx, "T1")
+	if !errors.Is(err, ErrInstallationNotFound) {
+		t.Fatalf("expected ErrInstallationNotFound after Delete, got %v", err)
+	}
+	_, err = store.FindByEnterprise(ctx, "E1")
+	if err != nil {
+		t.Fatalf("expected the organization-wide installation to be kept, got %v", err)
+	}
+	err = store.Delete(ctx, "", "E1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = store.FindByEnterprise(ctx, "E1")
+	if !errors.Is(err, ErrInstallationNotFound) {
+		t.Fatalf("expected ErrInstallationNotFound after Delete, got %v", err)
+	}
+}
+
+func TestMemoryInstallationStore(t *testing.T) {
+	testInstallationStore(t, NewMemoryInstallationStore())
+}
+
+func TestSQLInstallationStore(t *testing.T) {
+	tests := []struct {
+		name        string
+		opts        *SQLInstallationStoreOptions
+		table       string
+		placeholder string
+	}{
+		{name: "defaults", table: "jet_installations", placeholder: "?"},
+		{
+			name:        "custom",
+			opts:        &SQLInstallationStoreOptions{Table: "app.installs", Placeholder: func(n int) string { return fmt.Sprintf("$%d", n) }},
+			table:       "app.installs",
+			placeholder: "$1",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			db, fake := openFakeSQL(t)
+			err := CreateSQLInstallationTable(context.Background(), db, test.table)
+			if err != nil {
+				t.Fatal(err)
+			}
+			store, err := NewSQLInstallationStore(db, test.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			testInstallationStore(t, store)
+
+			for _, query := range fake.queries {
+				if !strings.Contains(query, test.table) {
+					t.Errorf("expected query to use table %q: %s", test.table, query)
+				}
+				if strings.Contains(query, "WHERE") && !strings.HasSuffix(query, "= "+test.placeholder) {
+					t.Errorf("expected query to use placeholder %q: %s", test.placeholder, query)
+				}
+			}
+		})
+	}
+}
+
+func TestSQLInstallationStoreRejectsInvalidTables(t *testing.T) {
+	db, _ := openFakeSQL(t)
+	_, err := NewSQLInstallationStore(db, &SQLInstallationStoreOptions{Table: "installs; DROP TABLE users"})
+	if err == nil {
+		t.Error("expected the table name to be rejected")
+	}
+	err = CreateSQLInstallationTable(context.Background(), db, "1installs")
+	if err == nil {
+		t.Error("expected the table name to be rejected")
+	}
+}
+
+// fakeSQL is a minimal database/sql driver which only understands the queries of sqlInstallationStore,
+// so that it can be tested without adding a real driver to the dependencies
+type fakeSQL struct {
+	lock    sync.Mutex
+	tables  map[string]map[string]string
+	queries []string
+}
+
+var (
+	fakeSQLCount int
+	fakeSQLLock  sync.Mutex
+)
+
+func openFakeSQL(t *testing.T) (*sql.DB, *fakeSQL) {
+	fakeSQLLock.Lock()
+	fakeSQLCount++
+	name := fmt.Sprintf("jet-fake-%d", fakeSQLCount)
+	fakeSQLLock.Unlock()
+
+	fake := &fakeSQL{tables: make(map[string]map[string]string)}
+	sql.Register(name, fake)
+	db, err := sql.Open(name, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		db.Close()
+	})
+	return db, fake
+}
+
+func (me *fakeSQL) Open(name string) (driver.Conn, error) {
+	return &fakeSQLConn{db: me}, nil
+}
+
+func (me *fakeSQL) exec(query string, args []driver.Value) ([]string, error) {
+	me.lock.Lock()
+	defer me.lock.Unlock()
+
+	fields := strings.Fields(query)
+	if strings.HasPrefix(query, "CREATE TABLE IF NOT EXISTS ") {
+		if me.tables[fields[5]] == nil {
+			me.tables[fields[5]] = make(map[string]string)
+		}
+		return nil, nil
+	}
+
+	me.queries = append(me.queries, query)
+	var table map[string]string
+	switch {
+	case strings.HasPrefix(query, "SELECT data FROM "):
+		table = me.tables[fields[3]]
+	case strings.HasPrefix(query, "DELETE FROM "), strings.HasPrefix(query, "INSERT INTO "):
+		table = me.tables[fields[2]]
+	}
+	if table == nil {
+		return nil, fmt.Errorf("unsupported query: %s", query)
+	}
+
+	key, _ := args[0].(string)
+	switch fields[0] {
+	case "SELECT":
+		data, found := table[key]
+		if !found {
+			return []string{}, nil
+		}
+		return []string{data}, nil
+	case "DELETE":
+		delete(table, key)
+	case "INSERT":
+		if _, found := table[key]; found {
+			return nil, fmt.Errorf("duplicate key: %s", key)
+		}
+		table[key], _ = args[3].(string)
+	}
+	return nil, nil
+}
+
+type fakeSQLConn struct {
+	db *fakeSQL
+}
+
+func (me *fakeSQLConn) Prepare(query string) (driver.Stmt, error) {
+	return &fakeSQLStmt{db: me.db, query: query}, nil
+}
+
+func (me *fakeSQLConn) Close() error {
+	return nil
+}
+
+// transactions are not isolated, which is enough for these tests
+func (me *fakeSQLConn) Begin() (driver.Tx, error) {
+	return me, nil
+}
+
+func (me *fakeSQLConn) Commit() error {
+	return nil
+}
+
+func (me *fakeSQLConn) Rollback() error {
+	return nil
+}
+
+type fakeSQLStmt struct {
+	db    *fakeSQL
+	query string
+}
+
+func (me *fakeSQLStmt) Close() error {
+	return nil
+}
+
+func (me *fakeSQLStmt) NumInput() int {
+	return -1
+}
+
+func (me *fakeSQLStmt) Exec(args []driver.Value) (driver.Result, error) {
+	_, err := me.db.exec(me.query, args)
+	return driver.RowsAffected(1), err
+}
+
+func (me *fakeSQLStmt) Query(args []driver.Value) (driver.Rows, error) {
+	values, err := me.db.exec(me.query, args)
+	if err != nil {
+		return nil, err
+	}
+	return &fakeSQLRows{values: values}, nil
+}
+
+type fakeSQLRows struct {
+	values []string
+}
+
+func (me *fakeSQLRows) Columns() []string {
+	return []string{"data"}
+}
+
+func (me *fakeSQLRows) Close() error {
+	return nil
+}
+
+func (me *fakeSQLRows) Next(dest []driver.Value) error {
+	if len(me.values) == 0 {
+		return io.EOF
+	}
+	dest[0] = me.values[0]
+	me.values = me.values[1:]
+	return nil
+}
@@ -0,0 +1,42 @@
+package jet
+
+import (
+	"context"
+	"errors"
+
+	"github.com/slack-go/slack"
+)
+
+var ErrUnsupportedInteraction = errors.New("unsupported interaction")
+
+// InteractionHandler handles interactions which are not supported by jet, the returned value (if not nil) is sent back to Slack as JSON.
+type InteractionHandler func(ctx Context, args slack.InteractionCallback) (any, error)
+
+// interactionData is attached to the context given to callbacks, which only receive a `context.Context`
+type interactionData struct {
+	triggerID string
+	openModal func(flow *FlowHandle, props FlowProps) error
+}
+
+type interactionKey struct{}
+
+func withInteraction(ctx context.Context, data *interactionData) context.Context {
+	return context.WithValue(ctx, interactionKey{}, data)
+}
+
+func interactionFrom(ctx context.Context) *interactionData {
+	data, _ := ctx.Value(interactionKey{}).(*interactionData)
+	return data
+}
+
+// TriggerIDFrom returns the trigger ID of the interaction being handled (e.g. in a Callback), empty if there is none.
+func TriggerIDFrom(ctx context.Context) string {
+	if jetCtx, ok := ctx.(Context); ok {
+		return jetCtx.TriggerID()
+	}
+	data := interactionFrom(ctx)
+	if data == nil {
+		return ""
+	}
+	return data.triggerID
+}
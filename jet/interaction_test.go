@@ -0,0 +1,136 @@
+package jet_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/LouisBrunner/jet/jet"
+	"github.com/LouisBrunner/jet/jet/jettest"
+	"github.com/slack-go/slack"
+)
+
+func button(actionID, label string) slack.Block {
+	return slack.NewActionBlock("actions", slack.NewButtonBlockElement(actionID, "", slack.NewTextBlockObject(slack.PlainTextType, label, false, false)))
+}
+
+func TestModalOpenerInCallback(t *testing.T) {
+	srv := jettest.NewServer()
+	defer srv.Close()
+
+	builder := jet.NewBuilder()
+	var details *jet.FlowHandle
+	opener := func(ctx jet.RenderContext) (string, error) {
+		modals := jet.UseModalOpener(ctx)
+		return jet.UseCallback(ctx, func(ctx context.Context, args slack.BlockAction) error {
+			if jet.TriggerIDFrom(ctx) == "" {
+				t.Error("expected a trigger ID in the callback")
+			}
+			return modals.Open(details, nil)
+		})
+	}
+	details, err := builder.AddFlow(jet.NewFlow("details", func(ctx jet.RenderContext, props jet.FlowProps) (*jet.RenderedFlow, error) {
+		more, err := opener(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return &jet.RenderedFlow{
+			Blocks:   slack.Blocks{BlockSet: []slack.Block{button(more, "More")}},
+			ForModal: &jet.ModalConfig{Title: slack.NewTextBlockObject(slack.PlainTextType, "Details", false, false)},
+		}, nil
+	}, nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	summary, err := builder.AddFlow(jet.NewFlow("summary", func(ctx jet.RenderContext, props jet.FlowProps) (*jet.RenderedFlow, error) {
+		open, err := opener(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return &jet.RenderedFlow{
+			Text:   "summary",
+			Blocks: slack.Blocks{BlockSet: []slack.Block{button(open, "Details")}},
+		}, nil
+	}, nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	app := builder.AddSlash("/summary", func(ctx jet.Context, args slack.SlashCommand) (*jet.Message, error) {
+		return ctx.StartFlow(summary, nil)
+	}).Build(srv.Options(jet.Options{}))
+
+	ctx := context.Background()
+	msg, err := srv.SlashCommand(ctx, app, "/summary", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = srv.ClickButton(ctx, app, msg, "jet_summary_cb_0", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	views := srv.Views()
+	if len(views) != 1 {
+		t.Fatalf("expected a modal to be opened, got %d", len(views))
+	}
+
+	// from a modal, the new one is pushed on the stack
+	_, err = srv.ClickViewButton(ctx, app, &views[0], "jet_details_cb_0", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	views = srv.Views()
+	if len(views) != 2 || views[1].RootViewID != views[0].ID {
+		t.Fatalf("expected a modal to be pushed, got %+v", views)
+	}
+}
+
+func TestModalOpenerOutsideOfInteractions(t *testing.T) {
+	var openErr error
+	render, err := jet.RenderForTest(jet.NewFlow("opener", func(ctx jet.RenderContext, props jet.FlowProps) (*jet.RenderedFlow, error) {
+		modals := jet.UseModalOpener(ctx)
+		open, err := jet.UseCallback(ctx, func(ctx context.Context, args slack.BlockAction) error {
+			openErr = modals.Open(nil, nil)
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		return &jet.RenderedFlow{
+			Blocks: slack.Blocks{BlockSet: []slack.Block{button(open, "Open")}},
+		}, nil
+	}, nil), nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = render.Trigger(render.CallbackIDs[0], slack.BlockAction{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if openErr == nil {
+		t.Fatal("expected an error")
+	}
+	if jet.TriggerIDFrom(context.Background()) != "" {
+		t.Fatal("expected no trigger ID")
+	}
+}
+
+func TestUnknownInteractions(t *testing.T) {
+	ctx := context.Background()
+	interaction := slack.InteractionCallback{Type: "workflow_step_edit", User: slack.User{ID: "U2"}}
+
+	_, err := jet.NewBuilder().Build(jet.Options{}).HandleInteraction(ctx, interaction)
+	if !errors.Is(err, jet.ErrUnsupportedInteraction) {
+		t.Errorf("expected ErrUnsupportedInteraction, got %v", err)
+	}
+
+	app := jet.NewBuilder().HandleUnknownInteraction(func(ctx jet.Context, args slack.InteractionCallback) (any, error) {
+		return map[string]string{"user": ctx.Source().UserID}, nil
+	}).Build(jet.Options{})
+	res, err := app.HandleInteraction(ctx, interaction)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if body, ok := res.(map[string]string); !ok || body["user"] != "U2" {
+		t.Errorf("expected the fallback response, got %+v", res)
+	}
+}
@@ -0,0 +1,6 @@
+package jet
+
+import "github.com/slack-go/slack"
+
+// InteractiveMessageHandler handles a click on a legacy attachment button or menu, the returned message (if not nil) replaces the original one.
+type InteractiveMessageHandler = func(ctx Context, args slack.InteractionCallback) (*Message, error)
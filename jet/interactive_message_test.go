@@ -0,0 +1,44 @@
+package jet_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/LouisBrunner/jet/jet"
+	"github.com/slack-go/slack"
+)
+
+func TestInteractiveMessage(t *testing.T) {
+	app := jet.NewBuilder().HandleInteractiveMessage("approval", func(ctx jet.Context, args slack.InteractionCallback) (*jet.Message, error) {
+		return &jet.Message{Msg: slack.Msg{Text: "approved by <@" + ctx.Source().UserID + ">"}}, nil
+	}).Build(jet.Options{})
+
+	interaction := func(callbackID string) slack.InteractionCallback {
+		return slack.InteractionCallback{
+			Type:       slack.InteractionTypeInteractionMessage,
+			CallbackID: callbackID,
+			User:       slack.User{ID: "U2"},
+			ActionCallback: slack.ActionCallbacks{
+				AttachmentActions: []*slack.AttachmentAction{{Name: "approve", Value: "yes"}},
+			},
+		}
+	}
+
+	ctx := context.Background()
+	res, err := app.HandleInteraction(ctx, interaction("approval"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg, ok := res.(*jet.Message)
+	if !ok {
+		t.Fatalf("expected a message, got %T", res)
+	}
+	if msg.Text != "approved by <@U2>" || !msg.ReplaceOriginal {
+		t.Errorf("expected the original message to be replaced, got %+v", msg.Msg)
+	}
+
+	_, err = app.HandleInteraction(ctx, interaction("unknown"))
+	if err == nil {
+		t.Error("expected unknown interactive messages to fail without a fallback")
+	}
+}
@@ -0,0 +1,63 @@
+package jetpreview
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/LouisBrunner/jet/jet"
+	"github.com/slack-go/slack"
+)
+
+const blockKitBuilderURL = "https://app.slack.com/block-kit-builder"
+
+// Payload returns the JSON understood by the Block Kit Builder, as a modal if the flow renders one.
+func Payload(render *jet.TestRender) ([]byte, error) {
+	modal := render.Rendered.ForModal
+	if modal == nil {
+		return render.BlocksJSON()
+	}
+	return json.MarshalIndent(struct {
+		Type   slack.ViewType         `json:"type"`
+		Title  *slack.TextBlockObject `json:"title,omitempty"`
+		Submit *slack.TextBlockObject `json:"submit,omitempty"`
+		Close  *slack.TextBlockObject `json:"close,omitempty"`
+		Blocks []slack.Block          `json:"blocks"`
+	}{
+		Type:   slack.VTModal,
+		Title:  modal.Title,
+		Submit: modal.Submit,
+		Close:  modal.Close,
+		Blocks: render.Message.Blocks.BlockSet,
+	}, "", "  ")
+}
+
+// BuilderURL returns a link which opens the render in the Block Kit Builder.
+// The team ID is optional, without it Slack will ask which workspace to use.
+func BuilderURL(render *jet.TestRender, teamID string) (string, error) {
+	payload, err := Payload(render)
+	if err != nil {
+		return "", fmt.Errorf("failed to serialize blocks: %w", err)
+	}
+	compact, err := compactJSON(payload)
+	if err != nil {
+		return "", err
+	}
+	base := blockKitBuilderURL
+	if teamID != "" {
+		base += "/" + teamID
+	}
+	// the builder decodes the fragment like `decodeURIComponent`, which doesn't understand `+`
+	return base + "#" + strings.ReplaceAll(url.QueryEscape(compact), "+", "%20"), nil
+}
+
+func compactJSON(payload []byte) (string, error) {
+	var compact bytes.Buffer
+	err := json.Compact(&compact, payload)
+	if err != nil {
+		return "", fmt.Errorf("failed to compact blocks: %w", err)
+	}
+	return compact.String(), nil
+}
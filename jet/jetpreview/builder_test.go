@@ -0,0 +1,147 @@
+package jetpreview_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/LouisBrunner/jet/jet"
+	"github.com/LouisBrunner/jet/jet/jetpreview"
+	"github.com/slack-go/slack"
+)
+
+func counterFlow(modal *jet.ModalConfig) jet.Flow {
+	return jet.NewFlow("counter", func(ctx jet.RenderContext, props jet.FlowProps) (*jet.RenderedFlow, error) {
+		count, setCount, err := jet.UseState(ctx, 0)
+		if err != nil {
+			return nil, err
+		}
+		increment, err := jet.UseCallback(ctx, func(ctx context.Context, args slack.BlockAction) error {
+			return setCount(count + 1)
+		})
+		if err != nil {
+			return nil, err
+		}
+		return &jet.RenderedFlow{
+			Text: fmt.Sprintf("count: %d", count),
+			Blocks: slack.Blocks{BlockSet: []slack.Block{
+				slack.NewSectionBlock(slack.NewTextBlockObject(slack.MarkdownType, fmt.Sprintf("Count is *%d* & rising", count), false, false), nil, nil),
+				slack.NewActionBlock("actions", slack.NewButtonBlockElement(increment, "", slack.NewTextBlockObject(slack.PlainTextType, "Increment", false, false))),
+			}},
+			ForModal: modal,
+		}, nil
+	}, nil)
+}
+
+type builderPayload struct {
+	Type   string         `json:"type"`
+	Title  map[string]any `json:"title"`
+	Submit map[string]any `json:"submit"`
+	Blocks []struct {
+		Text struct {
+			Text string `json:"text"`
+		} `json:"text"`
+	} `json:"blocks"`
+}
+
+func TestPayload(t *testing.T) {
+	tests := []struct {
+		name     string
+		modal    *jet.ModalConfig
+		expected string
+		title    string
+	}{
+		{name: "message"},
+		{
+			name: "modal",
+			modal: &jet.ModalConfig{
+				Title:  slack.NewTextBlockObject(slack.PlainTextType, "Counter", false, false),
+				Submit: slack.NewTextBlockObject(slack.PlainTextType, "Save", false, false),
+			},
+			expected: "modal",
+			title:    "Counter",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			render, err := jet.RenderForTest(counterFlow(test.modal), nil, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			payload, err := jetpreview.Payload(render)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var decoded builderPayload
+			err = json.Unmarshal(payload, &decoded)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if decoded.Type != test.expected {
+				t.Errorf("expected type %q, got %q", test.expected, decoded.Type)
+			}
+			if len(decoded.Blocks) != 2 {
+				t.Errorf("expected the 2 blocks of the flow, got %d", len(decoded.Blocks))
+			}
+			title, _ := decoded.Title["text"].(string)
+			if title != test.title {
+				t.Errorf("expected title %q, got %q", test.title, title)
+			}
+			if test.modal == nil && decoded.Submit != nil {
+				t.Errorf("expected no submit button for a message, got %v", decoded.Submit)
+			}
+		})
+	}
+}
+
+func TestBuilderURL(t *testing.T) {
+	render, err := jet.RenderForTest(counterFlow(nil), nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		teamID string
+		prefix string
+	}{
+		{name: "without team", prefix: "https://app.slack.com/block-kit-builder#"},
+		{name: "with team", teamID: "T1", prefix: "https://app.slack.com/block-kit-builder/T1#"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			link, err := jetpreview.BuilderURL(render, test.teamID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			fragment, found := strings.CutPrefix(link, test.prefix)
+			if !found {
+				t.Fatalf("expected %q to start with %q", link, test.prefix)
+			}
+			if strings.Contains(fragment, "+") {
+				t.Errorf("expected spaces to be escaped as %%20, got %q", fragment)
+			}
+			decoded, err := url.PathUnescape(fragment)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if strings.Contains(decoded, "\n") {
+				t.Errorf("expected compact JSON, got %q", decoded)
+			}
+			var payload builderPayload
+			err = json.Unmarshal([]byte(decoded), &payload)
+			if err != nil {
+				t.Fatalf("expected the fragment to be the payload: %v", err)
+			}
+			if len(payload.Blocks) != 2 {
+				t.Fatalf("expected the 2 blocks of the flow, got %d", len(payload.Blocks))
+			}
+			if payload.Blocks[0].Text.Text != "Count is *0* & rising" {
+				t.Errorf("expected the text to survive the round-trip, got %q", payload.Blocks[0].Text.Text)
+			}
+		})
+	}
+}
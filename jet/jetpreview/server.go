@@ -0,0 +1,217 @@
+package jetpreview
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"sync"
+
+	"github.com/LouisBrunner/jet/jet"
+	"github.com/slack-go/slack"
+)
+
+// Preview is a flow rendered with example props.
+type Preview struct {
+	Name    string
+	Flow    jet.Flow
+	Props   jet.FlowProps
+	Options *jet.TestRenderOptions
+}
+
+type session struct {
+	preview Preview
+	// every render since the start, the last one is the current one
+	history []*jet.TestRender
+	lastErr error
+}
+
+// Server is a local HTTP server which renders the previews and lets you click through their callbacks.
+// Everything is rendered offline, using the same logic as when the flow is on Slack, nothing is sent to Slack.
+type Server struct {
+	TeamID string
+
+	previews []Preview
+	mux      *http.ServeMux
+
+	lock     sync.Mutex
+	seq      int
+	sessions map[string]*session
+}
+
+func NewServer(previews ...Preview) *Server {
+	me := &Server{
+		previews: previews,
+		mux:      http.NewServeMux(),
+		sessions: make(map[string]*session),
+	}
+	me.mux.HandleFunc("GET /{$}", me.handleIndex)
+	me.mux.HandleFunc("POST /previews/{name}", me.handleStart)
+	me.mux.HandleFunc("GET /sessions/{id}", me.handleSession)
+	me.mux.HandleFunc("GET /sessions/{id}/blocks.json", me.handleJSON)
+	me.mux.HandleFunc("POST /sessions/{id}/trigger", me.handleTrigger)
+	me.mux.HandleFunc("POST /sessions/{id}/back", me.handleBack)
+	return me
+}
+
+func (me *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	me.mux.ServeHTTP(w, r)
+}
+
+func (me *Server) handleIndex(w http.ResponseWriter, r *http.Request) {
+	renderPage(w, "index", me.previews)
+}
+
+func (me *Server) handleStart(w http.ResponseWriter, r *http.Request) {
+	idx := slices.IndexFunc(me.previews, func(preview Preview) bool {
+		return preview.Name == r.PathValue("name")
+	})
+	if idx == -1 {
+		http.NotFound(w, r)
+		return
+	}
+	preview := me.previews[idx]
+
+	render, err := jet.RenderForTest(preview.Flow, preview.Props, preview.Options)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to render %q: %v", preview.Name, err), http.StatusInternalServerError)
+		return
+	}
+
+	me.lock.Lock()
+	me.seq += 1
+	id := strconv.Itoa(me.seq)
+	me.sessions[id] = &session{
+		preview: preview,
+		history: []*jet.TestRender{render},
+	}
+	me.lock.Unlock()
+
+	http.Redirect(w, r, "/sessions/"+id, http.StatusSeeOther)
+}
+
+func (me *Server) getSession(w http.ResponseWriter, r *http.Request) (string, *session) {
+	id := r.PathValue("id")
+	sess, found := me.sessions[id]
+	if !found {
+		http.NotFound(w, r)
+		return "", nil
+	}
+	return id, sess
+}
+
+type sessionPage struct {
+	ID         string
+	Name       string
+	Text       string
+	Blocks     []map[string]any
+	JSON       string
+	BuilderURL string
+	Callbacks  []string
+	CanGoBack  bool
+	Error      error
+}
+
+func (me *Server) handleSession(w http.ResponseWriter, r *http.Request) {
+	me.lock.Lock()
+	defer me.lock.Unlock()
+	id, sess := me.getSession(w, r)
+	if sess == nil {
+		return
+	}
+	render := sess.history[len(sess.history)-1]
+
+	payload, err := Payload(render)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	var blocks struct {
+		Blocks []map[string]any `json:"blocks"`
+	}
+	err = json.Unmarshal(payload, &blocks)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	builderURL, err := BuilderURL(render, me.TeamID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	renderPage(w, "session", sessionPage{
+		ID:         id,
+		Name:       sess.preview.Name,
+		Text:       render.Message.Text,
+		Blocks:     blocks.Blocks,
+		JSON:       string(payload),
+		BuilderURL: builderURL,
+		Callbacks:  render.CallbackIDs,
+		CanGoBack:  len(sess.history) > 1,
+		Error:      sess.lastErr,
+	})
+}
+
+func (me *Server) handleJSON(w http.ResponseWriter, r *http.Request) {
+	me.lock.Lock()
+	defer me.lock.Unlock()
+	_, sess := me.getSession(w, r)
+	if sess == nil {
+		return
+	}
+	payload, err := Payload(sess.history[len(sess.history)-1])
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(payload)
+}
+
+func (me *Server) handleTrigger(w http.ResponseWriter, r *http.Request) {
+	me.lock.Lock()
+	defer me.lock.Unlock()
+	id, sess := me.getSession(w, r)
+	if sess == nil {
+		return
+	}
+
+	actionID := r.FormValue("action_id")
+	render, err := sess.history[len(sess.history)-1].Trigger(actionID, slack.BlockAction{
+		ActionID: actionID,
+		BlockID:  r.FormValue("block_id"),
+		Value:    r.FormValue("value"),
+		SelectedOption: slack.OptionBlockObject{
+			Value: r.FormValue("value"),
+		},
+	})
+	sess.lastErr = err
+	if err == nil {
+		sess.history = append(sess.history, render)
+	}
+	http.Redirect(w, r, "/sessions/"+id, http.StatusSeeOther)
+}
+
+func (me *Server) handleBack(w http.ResponseWriter, r *http.Request) {
+	me.lock.Lock()
+	defer me.lock.Unlock()
+	id, sess := me.getSession(w, r)
+	if sess == nil {
+		return
+	}
+	if len(sess.history) > 1 {
+		sess.history = sess.history[:len(sess.history)-1]
+	}
+	sess.lastErr = nil
+	http.Redirect(w, r, "/sessions/"+id, http.StatusSeeOther)
+}
+
+func renderPage(w http.ResponseWriter, name string, data any) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	err := pages.ExecuteTemplate(w, name, data)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,176 @@
+package jetpreview_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/LouisBrunner/jet/jet"
+	"github.com/LouisBrunner/jet/jet/jetpreview"
+)
+
+type previewClient struct {
+	t      *testing.T
+	server *httptest.Server
+	client *http.Client
+}
+
+func newPreviewClient(t *testing.T, previews ...jetpreview.Preview) *previewClient {
+	server := httptest.NewServer(jetpreview.NewServer(previews...))
+	t.Cleanup(server.Close)
+	return &previewClient{
+		t:      t,
+		server: server,
+		// keep the redirects visible so we can follow the sessions
+		client: &http.Client{CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		}},
+	}
+}
+
+func (me *previewClient) post(path string, form url.Values) *http.Response {
+	me.t.Helper()
+	res, err := me.client.PostForm(me.server.URL+path, form)
+	if err != nil {
+		me.t.Fatal(err)
+	}
+	res.Body.Close()
+	return res
+}
+
+func (me *previewClient) get(path string) (int, string) {
+	me.t.Helper()
+	res, err := me.client.Get(me.server.URL + path)
+	if err != nil {
+		me.t.Fatal(err)
+	}
+	defer res.Body.Close()
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		me.t.Fatal(err)
+	}
+	return res.StatusCode, string(body)
+}
+
+func (me *previewClient) text(session string) string {
+	me.t.Helper()
+	status, body := me.get(session + "/blocks.json")
+	if status != http.StatusOK {
+		me.t.Fatalf("expected the session to exist, got %d: %s", status, body)
+	}
+	var payload struct {
+		Blocks []struct {
+			Text struct {
+				Text string `json:"text"`
+			} `json:"text"`
+		} `json:"blocks"`
+	}
+	err := json.Unmarshal([]byte(body), &payload)
+	if err != nil {
+		me.t.Fatal(err)
+	}
+	return payload.Blocks[0].Text.Text
+}
+
+func expectRedirect(t *testing.T, res *http.Response, location string) {
+	t.Helper()
+	if res.StatusCode != http.StatusSeeOther {
+		t.Fatalf("expected a redirect, got %d", res.StatusCode)
+	}
+	if location != "" && res.Header.Get("Location") != location {
+		t.Fatalf("expected a redirect to %q, got %q", location, res.Header.Get("Location"))
+	}
+}
+
+func TestServerIndex(t *testing.T) {
+	client := newPreviewClient(t, jetpreview.Preview{Name: "counter", Flow: counterFlow(nil)})
+
+	status, body := client.get("/")
+	if status != http.StatusOK {
+		t.Fatalf("expected the index, got %d", status)
+	}
+	if !strings.Contains(body, `action="/previews/counter"`) {
+		t.Errorf("expected the preview to be listed, got %s", body)
+	}
+
+	res := client.post("/previews/unknown", nil)
+	if res.StatusCode != http.StatusNotFound {
+		t.Errorf("expected unknown previews to be rejected, got %d", res.StatusCode)
+	}
+	status, _ = client.get("/sessions/42")
+	if status != http.StatusNotFound {
+		t.Errorf("expected unknown sessions to be rejected, got %d", status)
+	}
+}
+
+func TestServerNavigation(t *testing.T) {
+	client := newPreviewClient(t, jetpreview.Preview{Name: "counter", Flow: counterFlow(nil)})
+
+	res := client.post("/previews/counter", nil)
+	expectRedirect(t, res, "/sessions/1")
+	session := res.Header.Get("Location")
+
+	render, err := jet.RenderForTest(counterFlow(nil), nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	trigger := url.Values{"action_id": {render.CallbackIDs[0]}, "block_id": {"actions"}}
+
+	status, body := client.get(session)
+	if status != http.StatusOK {
+		t.Fatalf("expected the session page, got %d", status)
+	}
+	if !strings.Contains(body, `value="`+render.CallbackIDs[0]+`"`) {
+		t.Errorf("expected the button to be rendered, got %s", body)
+	}
+	if strings.Contains(body, "/back") {
+		t.Error("expected no way back on the first render")
+	}
+
+	expectRedirect(t, client.post(session+"/trigger", trigger), session)
+	expectRedirect(t, client.post(session+"/trigger", trigger), session)
+	if text := client.text(session); text != "Count is *2* & rising" {
+		t.Errorf("expected the callbacks to update the state, got %q", text)
+	}
+	_, body = client.get(session)
+	if !strings.Contains(body, session+"/back") {
+		t.Error("expected a way back after a callback")
+	}
+
+	expectRedirect(t, client.post(session+"/trigger", url.Values{"action_id": {"jet_counter_cb_9"}}), session)
+	_, body = client.get(session)
+	if !strings.Contains(body, `class="error"`) {
+		t.Error("expected unknown callbacks to show an error")
+	}
+	if text := client.text(session); text != "Count is *2* & rising" {
+		t.Errorf("expected failed callbacks to keep the render, got %q", text)
+	}
+
+	expectRedirect(t, client.post(session+"/back", nil), session)
+	_, body = client.get(session)
+	if strings.Contains(body, `class="error"`) {
+		t.Error("expected going back to clear the error")
+	}
+	if text := client.text(session); text != "Count is *1* & rising" {
+		t.Errorf("expected to go back to the previous render, got %q", text)
+	}
+
+	expectRedirect(t, client.post(session+"/back", nil), session)
+	expectRedirect(t, client.post(session+"/back", nil), session)
+	if text := client.text(session); text != "Count is *0* & rising" {
+		t.Errorf("expected to stop at the first render, got %q", text)
+	}
+
+	res = client.post("/previews/counter", nil)
+	expectRedirect(t, res, "/sessions/2")
+	if text := client.text(res.Header.Get("Location")); text != "Count is *0* & rising" {
+		t.Errorf("expected new sessions to start from scratch, got %q", text)
+	}
+	if text := client.text(session); text != "Count is *0* & rising" {
+		t.Errorf("expected sessions to be independent, got %q", text)
+	}
+}
@@ -0,0 +1,91 @@
+package jetpreview
+
+import (
+	"html/template"
+)
+
+// a rough approximation of Block Kit, use the Block Kit Builder link to see the real thing
+var pages = template.Must(template.New("").Funcs(template.FuncMap{
+	"elementOf": elementOf,
+}).Parse(`
+{{define "header"}}<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Jet preview</title>
+<style>
+body { font-family: sans-serif; max-width: 960px; margin: 2em auto; }
+.block { border-left: 3px solid #ddd; padding: 0.5em 1em; margin: 0.5em 0; }
+.header { font-size: 1.4em; font-weight: bold; }
+.context { color: #666; font-size: 0.9em; }
+.element { display: inline-block; margin: 0.25em; }
+.error { color: #b00; }
+pre { background: #f4f4f4; padding: 1em; overflow: auto; }
+</style>
+</head>
+<body>
+{{end}}
+
+{{define "footer"}}</body>
+</html>
+{{end}}
+
+{{define "index"}}{{template "header"}}
+<h1>Previews</h1>
+{{range .}}<form method="post" action="/previews/{{.Name}}"><button type="submit">{{.Name}}</button></form>
+{{else}}<p>No previews registered.</p>
+{{end}}
+{{template "footer"}}{{end}}
+
+{{define "text"}}{{with .}}{{.text}}{{end}}{{end}}
+
+{{define "element"}}<form class="element" method="post" action="/sessions/{{.SessionID}}/trigger">
+<input type="hidden" name="action_id" value="{{.Element.action_id}}">
+<input type="hidden" name="block_id" value="{{with .BlockID}}{{.}}{{end}}">
+{{if eq .Element.type "button"}}<input type="hidden" name="value" value="{{with .Element.value}}{{.}}{{end}}">
+<button type="submit">{{template "text" .Element.text}}</button>
+{{else if .Element.options}}<select name="value">{{range .Element.options}}<option value="{{.value}}">{{template "text" .text}}</option>{{end}}</select>
+<button type="submit">Select</button>
+{{else}}<input type="text" name="value" placeholder="{{.Element.type}}">
+<button type="submit">Send</button>
+{{end}}</form>{{end}}
+
+{{define "session"}}{{template "header"}}{{$ := .}}
+<h1>{{.Name}}</h1>
+<p><a href="/">All previews</a> · <a href="/sessions/{{.ID}}/blocks.json">JSON</a> · <a href="{{.BuilderURL}}" target="_blank">Open in Block Kit Builder</a></p>
+{{if .CanGoBack}}<form method="post" action="/sessions/{{.ID}}/back"><button type="submit">Back</button></form>{{end}}
+{{with .Error}}<p class="error">{{.}}</p>{{end}}
+{{with .Text}}<p><em>Notification text:</em> {{.}}</p>{{end}}
+
+{{range .Blocks}}<div class="block {{.type}}">
+{{if eq .type "divider"}}<hr>
+{{else if eq .type "image"}}<img src="{{.image_url}}" alt="{{with .alt_text}}{{.}}{{end}}" style="max-width: 100%">
+{{else if eq .type "input"}}{{$block := .}}<label>{{template "text" .label}}</label>
+{{with .element}}{{if .action_id}}{{template "element" (elementOf $.ID $block .)}}{{end}}{{end}}
+{{else}}{{template "text" .text}}
+{{range .fields}}<div>{{.text}}</div>{{end}}
+{{$block := .}}{{range .elements}}{{if .action_id}}{{template "element" (elementOf $.ID $block .)}}{{else}}<span>{{with .text}}{{.}}{{end}}{{with .alt_text}}{{.}}{{end}}</span>{{end}}{{end}}
+{{with .accessory}}{{if .action_id}}{{template "element" (elementOf $.ID $block .)}}{{end}}{{end}}
+{{end}}</div>
+{{end}}
+
+<h2>Callbacks</h2>
+<ul>{{range .Callbacks}}<li><code>{{.}}</code></li>{{end}}</ul>
+<h2>JSON</h2>
+<pre>{{.JSON}}</pre>
+{{template "footer"}}{{end}}
+`))
+
+type elementData struct {
+	SessionID string
+	BlockID   any
+	Element   map[string]any
+}
+
+func elementOf(sessionID string, block, element map[string]any) elementData {
+	return elementData{
+		SessionID: sessionID,
+		BlockID:   block["block_id"],
+		Element:   element,
+	}
+}
@@ -0,0 +1,344 @@
+package jettest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+
+	"github.com/slack-go/slack"
+)
+
+// parseMessageForm reads the fields shared by `chat.postMessage` and `chat.update`
+func parseMessageForm(r *http.Request) (slack.Msg, error) {
+	msg := slack.Msg{
+		Text:            r.PostForm.Get("text"),
+		ThreadTimestamp: r.PostForm.Get("thread_ts"),
+	}
+	if raw := r.PostForm.Get("blocks"); raw != "" {
+		err := json.Unmarshal([]byte(raw), &msg.Blocks)
+		if err != nil {
+			return msg, fmt.Errorf("invalid blocks: %w", err)
+		}
+	}
+	if raw := r.PostForm.Get("metadata"); raw != "" {
+		err := json.Unmarshal([]byte(raw), &msg.Metadata)
+		if err != nil {
+			return msg, fmt.Errorf("invalid metadata: %w", err)
+		}
+	}
+	return msg, nil
+}
+
+func (me *Server) postMessage(w http.ResponseWriter, r *http.Request) {
+	channelID := r.PostForm.Get("channel")
+	if channelID == "" {
+		writeError(w, "channel_not_found")
+		return
+	}
+	msg, err := parseMessageForm(r)
+	if err != nil {
+		writeError(w, "invalid_arguments")
+		return
+	}
+	msg.Type = slack.TYPE_MESSAGE
+
+	me.lock.Lock()
+	stored := me.addMessage(channelID, msg)
+	me.lock.Unlock()
+
+	writeJSON(w, map[string]any{
+		"channel": channelID,
+		"ts":      stored.Timestamp,
+		"message": stored.Msg,
+	})
+}
+
+func (me *Server) updateMessage(w http.ResponseWriter, r *http.Request) {
+	channelID := r.PostForm.Get("channel")
+	ts := r.PostForm.Get("ts")
+	msg, err := parseMessageForm(r)
+	if err != nil {
+		writeError(w, "invalid_arguments")
+		return
+	}
+
+	me.lock.Lock()
+	defer me.lock.Unlock()
+	_, stored := me.findMessage(channelID, ts)
+	if stored == nil {
+		writeError(w, "message_not_found")
+		return
+	}
+	stored.Text = msg.Text
+	stored.Blocks = msg.Blocks
+	stored.Metadata = msg.Metadata
+
+	writeJSON(w, map[string]any{
+		"channel": channelID,
+		"ts":      ts,
+		"text":    msg.Text,
+	})
+}
+
+func (me *Server) conversationsHistory(w http.ResponseWriter, r *http.Request) {
+	channelID := r.PostForm.Get("channel")
+	latest := r.PostForm.Get("latest")
+	inclusive := r.PostForm.Get("inclusive") == "1"
+	withMetadata := r.PostForm.Get("include_all_metadata") == "1"
+	limit, _ := strconv.Atoi(r.PostForm.Get("limit"))
+	if limit <= 0 {
+		limit = 100
+	}
+
+	me.lock.Lock()
+	defer me.lock.Unlock()
+	messages := []slack.Message{}
+	for _, msg := range slices.Backward(me.messages[channelID]) {
+		if latest != "" && (msg.Timestamp > latest || (!inclusive && msg.Timestamp == latest)) {
+			continue
+		}
+		if len(messages) == limit {
+			break
+		}
+		found := msg.Msg
+		if !withMetadata {
+			found.Metadata = slack.SlackMetadata{}
+		}
+		messages = append(messages, slack.Message{Msg: found})
+	}
+
+	writeJSON(w, map[string]any{
+		"messages": messages,
+		"has_more": false,
+	})
+}
+
+type viewRequest struct {
+	TriggerID  string     `json:"trigger_id"`
+	UserID     string     `json:"user_id"`
+	ViewID     string     `json:"view_id"`
+	ExternalID string     `json:"external_id"`
+	Hash       string     `json:"hash"`
+	View       slack.View `json:"view"`
+}
+
+func (me *Server) newView(req viewRequest) *View {
+	view := &View{
+		View:      req.View,
+		UserID:    req.UserID,
+		TriggerID: req.TriggerID,
+	}
+	view.ID = fmt.Sprintf("V%08d", me.nextSeq())
+	view.TeamID = me.TeamID
+	view.Hash = me.newTS()
+	if view.ExternalID == "" {
+		view.ExternalID = req.ExternalID
+	}
+	return view
+}
+
+func writeView(w http.ResponseWriter, view *View) {
+	writeJSON(w, map[string]any{
+		"view": view.View,
+	})
+}
+
+func (me *Server) openView(w http.ResponseWriter, r *http.Request) {
+	var req viewRequest
+	err := decodeJSON(r, &req)
+	if err != nil || req.TriggerID == "" {
+		writeError(w, "invalid_arguments")
+		return
+	}
+
+	me.lock.Lock()
+	defer me.lock.Unlock()
+	req.UserID = me.triggerUser(req.TriggerID)
+	view := me.newView(req)
+	view.RootViewID = view.ID
+	me.views[view.ID] = view
+	writeView(w, view)
+}
+
+func (me *Server) pushView(w http.ResponseWriter, r *http.Request) {
+	var req viewRequest
+	err := decodeJSON(r, &req)
+	if err != nil || req.TriggerID == "" {
+		writeError(w, "invalid_arguments")
+		return
+	}
+
+	me.lock.Lock()
+	defer me.lock.Unlock()
+	req.UserID = me.triggerUser(req.TriggerID)
+	view := me.newView(req)
+	// the pushed view goes on top of the latest modal of the user
+	for _, previous := range me.views {
+		if previous.UserID == view.UserID && previous.ID > view.PreviousViewID {
+			view.PreviousViewID = previous.ID
+			view.RootViewID = previous.RootViewID
+		}
+	}
+	if view.RootViewID == "" {
+		view.RootViewID = view.ID
+	}
+	me.views[view.ID] = view
+	writeView(w, view)
+}
+
+func (me *Server) updateView(w http.ResponseWriter, r *http.Request) {
+	var req viewRequest
+	err := decodeJSON(r, &req)
+	if err != nil {
+		writeError(w, "invalid_arguments")
+		return
+	}
+
+	me.lock.Lock()
+	defer me.lock.Unlock()
+	var existing *View
+	for _, view := range me.views {
+		if (req.ViewID != "" && view.ID == req.ViewID) || (req.ViewID == "" && req.ExternalID != "" && view.ExternalID == req.ExternalID) {
+			existing = view
+			break
+		}
+	}
+	if existing == nil {
+		writeError(w, "not_found")
+		return
+	}
+	if req.Hash != "" && req.Hash != existing.Hash {
+		writeError(w, "hash_conflict")
+		return
+	}
+
+	updated := *existing
+	updated.View = req.View
+	updated.ID = existing.ID
+	updated.TeamID = existing.TeamID
+	updated.RootViewID = existing.RootViewID
+	updated.PreviousViewID = existing.PreviousViewID
+	updated.Hash = me.newTS()
+	if updated.ExternalID == "" {
+		updated.ExternalID = existing.ExternalID
+	}
+	*existing = updated
+	writeView(w, existing)
+}
+
+func (me *Server) publishView(w http.ResponseWriter, r *http.Request) {
+	var req viewRequest
+	err := decodeJSON(r, &req)
+	if err != nil || req.UserID == "" {
+		writeError(w, "invalid_arguments")
+		return
+	}
+
+	me.lock.Lock()
+	defer me.lock.Unlock()
+	existing, found := me.homes[req.UserID]
+	if found && req.Hash != "" && req.Hash != existing.Hash {
+		writeError(w, "hash_conflict")
+		return
+	}
+	view := me.newView(req)
+	if found {
+		view.ID = existing.ID
+	}
+	view.Type = slack.VTHomeTab
+	me.homes[req.UserID] = view
+	writeView(w, view)
+}
+
+func (me *Server) triggerUser(triggerID string) string {
+	userID, found := me.triggers[triggerID]
+	if !found {
+		return me.UserID
+	}
+	return userID
+}
+
+func (me *Server) respond(w http.ResponseWriter, r *http.Request) {
+	var msg slack.Msg
+	err := decodeJSON(r, &msg)
+	if err != nil {
+		writeError(w, "invalid_payload")
+		return
+	}
+
+	me.lock.Lock()
+	defer me.lock.Unlock()
+	target, found := me.targets[r.PathValue("id")]
+	if !found {
+		writeError(w, "expired_url")
+		return
+	}
+	me.responses = append(me.responses, Response{
+		URL: me.server.URL + r.URL.Path,
+		Msg: msg,
+	})
+	me.applyResponse(target, msg)
+	writeJSON(w, map[string]any{})
+}
+
+func (me *Server) openDialog(w http.ResponseWriter, r *http.Request) {
+	var req slack.DialogTrigger
+	err := decodeJSON(r, &req)
+	if err != nil || req.TriggerID == "" {
+		writeError(w, "invalid_arguments")
+		return
+	}
+
+	me.lock.Lock()
+	defer me.lock.Unlock()
+	me.dialogs = append(me.dialogs, Dialog{
+		Dialog:    req.Dialog,
+		UserID:    me.triggerUser(req.TriggerID),
+		TriggerID: req.TriggerID,
+	})
+	writeJSON(w, map[string]any{})
+}
+
+func (me *Server) completeFunction(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		FunctionExecutionID string            `json:"function_execution_id"`
+		Outputs             map[string]string `json:"outputs"`
+		Error               string            `json:"error"`
+	}
+	err := decodeJSON(r, &req)
+	if err != nil || req.FunctionExecutionID == "" {
+		writeError(w, "invalid_arguments")
+		return
+	}
+
+	me.lock.Lock()
+	defer me.lock.Unlock()
+	me.functions[req.FunctionExecutionID] = FunctionResult{
+		ExecutionID: req.FunctionExecutionID,
+		Outputs:     req.Outputs,
+		Error:       req.Error,
+	}
+	writeJSON(w, map[string]any{})
+}
+
+// applyResponse mimics what Slack does with a `response_url` payload
+func (me *Server) applyResponse(target responseTarget, msg slack.Msg) {
+	idx, existing := me.findMessage(target.channelID, target.messageTS)
+	switch {
+	case msg.DeleteOriginal && existing != nil:
+		me.messages[target.channelID] = slices.Delete(me.messages[target.channelID], idx, idx+1)
+	case msg.ReplaceOriginal && existing != nil:
+		existing.Text = msg.Text
+		existing.Blocks = msg.Blocks
+		existing.Metadata = msg.Metadata
+		existing.ResponseType = msg.ResponseType
+	default:
+		msg.Type = slack.TYPE_MESSAGE
+		msg.ReplaceOriginal = false
+		msg.DeleteOriginal = false
+		msg.Timestamp = ""
+		me.addMessage(target.channelID, msg)
+	}
+}
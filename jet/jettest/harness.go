@@ -0,0 +1,317 @@
+package jettest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/LouisBrunner/jet/jet"
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+)
+
+// Options plugs the fake server into the given options, a default access token is provided if no other source is configured.
+func (me *Server) Options(opts jet.Options) jet.Options {
+	opts.APIURL = me.APIURL()
+	opts.HTTPClient = me.server.Client()
+	if opts.Credentials.GetAccessToken == nil && opts.Credentials.GetEnterpriseAccessToken == nil && opts.InstallationStore == nil {
+		opts.Credentials.GetAccessToken = func(teamID string) (string, error) {
+			return DefaultToken, nil
+		}
+	}
+	return opts
+}
+
+// SlashCommand runs a slash command (e.g. `/hello`) in the default channel.
+// The message returned by the App is stored in the channel like Slack would, it is returned if there is one.
+func (me *Server) SlashCommand(ctx context.Context, app jet.App, command, text string) (*Message, error) {
+	res := app.HandleSlashCommand(ctx, slack.SlashCommand{
+		TeamID:      me.TeamID,
+		ChannelID:   me.ChannelID,
+		UserID:      me.UserID,
+		Command:     command,
+		Text:        text,
+		TriggerID:   me.NewTriggerID(me.UserID),
+		ResponseURL: me.NewResponseURL(me.ChannelID, ""),
+	})
+	if res == nil {
+		return nil, nil
+	}
+
+	// go through JSON so the message looks like one coming from Slack
+	msg, err := roundTrip(res.Msg)
+	if err != nil {
+		return nil, err
+	}
+	stored := me.AddMessage(me.ChannelID, msg)
+	return &stored, nil
+}
+
+// ClickButton clicks on a button (or any element with an action ID) of a message posted in the fake server.
+func (me *Server) ClickButton(ctx context.Context, app jet.App, msg *Message, actionID, value string) (any, error) {
+	current := me.Message(msg.ChannelID, msg.Timestamp)
+	if current == nil {
+		return nil, fmt.Errorf("message %q not found in %q", msg.Timestamp, msg.ChannelID)
+	}
+	action, err := findAction(current.Blocks, actionID, value)
+	if err != nil {
+		return nil, err
+	}
+
+	return app.HandleInteraction(ctx, slack.InteractionCallback{
+		Type:        slack.InteractionTypeBlockActions,
+		Team:        slack.Team{ID: me.TeamID},
+		User:        slack.User{ID: me.UserID, TeamID: me.TeamID},
+		Channel:     slack.Channel{GroupConversation: slack.GroupConversation{Conversation: slack.Conversation{ID: current.ChannelID}}},
+		Message:     slack.Message{Msg: current.Msg},
+		TriggerID:   me.NewTriggerID(me.UserID),
+		ResponseURL: me.NewResponseURL(current.ChannelID, current.Timestamp),
+		Container: slack.Container{
+			Type:      "message",
+			ChannelID: current.ChannelID,
+			MessageTs: current.Timestamp,
+		},
+		ActionCallback: slack.ActionCallbacks{
+			BlockActions: []*slack.BlockAction{action},
+		},
+	})
+}
+
+// MessageShortcut runs a message shortcut on a message posted in the fake server.
+func (me *Server) MessageShortcut(ctx context.Context, app jet.App, msg *Message, callbackID string) error {
+	current := me.Message(msg.ChannelID, msg.Timestamp)
+	if current == nil {
+		return fmt.Errorf("message %q not found in %q", msg.Timestamp, msg.ChannelID)
+	}
+
+	_, err := app.HandleInteraction(ctx, slack.InteractionCallback{
+		Type:        slack.InteractionTypeMessageAction,
+		CallbackID:  callbackID,
+		Team:        slack.Team{ID: me.TeamID},
+		User:        slack.User{ID: me.UserID, TeamID: me.TeamID},
+		Channel:     slack.Channel{GroupConversation: slack.GroupConversation{Conversation: slack.Conversation{ID: current.ChannelID}}},
+		Message:     slack.Message{Msg: current.Msg},
+		TriggerID:   me.NewTriggerID(me.UserID),
+		ResponseURL: me.NewResponseURL(current.ChannelID, ""),
+	})
+	return err
+}
+
+// ClickViewButton clicks on a button (or any element with an action ID) of a modal or home tab stored in the fake server.
+func (me *Server) ClickViewButton(ctx context.Context, app jet.App, view *View, actionID, value string) (any, error) {
+	var current *View
+	if view.Type == slack.VTHomeTab {
+		current = me.HomeView(view.UserID)
+	} else {
+		current = me.View(view.ID)
+	}
+	if current == nil {
+		return nil, fmt.Errorf("view %q not found", view.ID)
+	}
+	action, err := findAction(current.Blocks, actionID, value)
+	if err != nil {
+		return nil, err
+	}
+
+	userID := current.UserID
+	if userID == "" {
+		userID = me.UserID
+	}
+	return app.HandleInteraction(ctx, slack.InteractionCallback{
+		Type:      slack.InteractionTypeBlockActions,
+		Team:      slack.Team{ID: me.TeamID},
+		User:      slack.User{ID: userID, TeamID: me.TeamID},
+		View:      current.View,
+		TriggerID: me.NewTriggerID(userID),
+		Container: slack.Container{
+			Type:   "view",
+			ViewID: current.ID,
+		},
+		ActionCallback: slack.ActionCallbacks{
+			BlockActions: []*slack.BlockAction{action},
+		},
+	})
+}
+
+// SubmitView submits a modal stored in the fake server with the given input values (keyed by block ID then action ID).
+// The returned value is the response the App sends back to Slack (e.g. validation errors).
+func (me *Server) SubmitView(ctx context.Context, app jet.App, view *View, values map[string]map[string]slack.BlockAction) (any, error) {
+	current := me.View(view.ID)
+	if current == nil {
+		return nil, fmt.Errorf("view %q not found", view.ID)
+	}
+	current.State = &slack.ViewState{Values: values}
+	return app.HandleInteraction(ctx, me.viewInteraction(slack.InteractionTypeViewSubmission, current))
+}
+
+// CloseView closes a modal stored in the fake server, the App is only notified if the modal was opened with `NotifyOnClose`.
+func (me *Server) CloseView(ctx context.Context, app jet.App, view *View) error {
+	current := me.View(view.ID)
+	if current == nil {
+		return fmt.Errorf("view %q not found", view.ID)
+	}
+	if !current.NotifyOnClose {
+		return nil
+	}
+	_, err := app.HandleInteraction(ctx, me.viewInteraction(slack.InteractionTypeViewClosed, current))
+	return err
+}
+
+func (me *Server) viewInteraction(kind slack.InteractionType, view *View) slack.InteractionCallback {
+	userID := view.UserID
+	if userID == "" {
+		userID = me.UserID
+	}
+	return slack.InteractionCallback{
+		Type: kind,
+		Team: slack.Team{ID: me.TeamID},
+		User: slack.User{ID: userID, TeamID: me.TeamID},
+		View: view.View,
+	}
+}
+
+// SuggestOptions types a query in an external select of a message posted in the fake server, it returns the options loaded by the App.
+func (me *Server) SuggestOptions(ctx context.Context, app jet.App, msg *Message, actionID, query string) (*slack.OptionsResponse, error) {
+	current := me.Message(msg.ChannelID, msg.Timestamp)
+	if current == nil {
+		return nil, fmt.Errorf("message %q not found in %q", msg.Timestamp, msg.ChannelID)
+	}
+
+	res, err := app.HandleInteraction(ctx, slack.InteractionCallback{
+		Type:     slack.InteractionTypeBlockSuggestion,
+		Team:     slack.Team{ID: me.TeamID},
+		User:     slack.User{ID: me.UserID, TeamID: me.TeamID},
+		Channel:  slack.Channel{GroupConversation: slack.GroupConversation{Conversation: slack.Conversation{ID: current.ChannelID}}},
+		Message:  slack.Message{Msg: current.Msg},
+		ActionID: actionID,
+		Value:    query,
+		Container: slack.Container{
+			Type:      "message",
+			ChannelID: current.ChannelID,
+			MessageTs: current.Timestamp,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	options, ok := res.(*slack.OptionsResponse)
+	if !ok {
+		return nil, fmt.Errorf("unexpected response to a block suggestion: %T", res)
+	}
+	return options, nil
+}
+
+// SendEvent delivers an Events API event (the `event` field of the callback, e.g. an `app_mention`) like Slack would.
+func (me *Server) SendEvent(ctx context.Context, app jet.App, event map[string]any) error {
+	raw, err := json.Marshal(map[string]any{
+		"type":    slackevents.CallbackEvent,
+		"team_id": me.TeamID,
+		"event":   event,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal event: %w", err)
+	}
+	parsed, err := slackevents.ParseEvent(raw, slackevents.OptionNoVerifyToken())
+	if err != nil {
+		return fmt.Errorf("failed to parse event: %w", err)
+	}
+	_, err = app.HandleEvent(ctx, parsed)
+	return err
+}
+
+// RunWorkflowFunction executes the workflow function with the given callback ID like Workflow Builder would, it returns the execution ID.
+// Use FunctionResult to check how it was completed.
+func (me *Server) RunWorkflowFunction(ctx context.Context, app jet.App, callbackID string, inputs map[string]any) (string, error) {
+	executionID := fmt.Sprintf("Fx%08d", me.nextID())
+	inner, err := json.Marshal(map[string]any{
+		"type": slackevents.FunctionExecuted,
+		"function": map[string]any{
+			"callback_id": callbackID,
+		},
+		"inputs":                inputs,
+		"function_execution_id": executionID,
+		"bot_access_token":      DefaultToken,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal event: %w", err)
+	}
+	raw := json.RawMessage(inner)
+	_, err = app.HandleEvent(ctx, slackevents.EventsAPIEvent{
+		TeamID: me.TeamID,
+		Type:   slackevents.CallbackEvent,
+		Data: &slackevents.EventsAPICallbackEvent{
+			Type:       slackevents.CallbackEvent,
+			TeamID:     me.TeamID,
+			InnerEvent: &raw,
+		},
+		InnerEvent: slackevents.EventsAPIInnerEvent{
+			Type: string(slackevents.FunctionExecuted),
+		},
+	})
+	return executionID, err
+}
+
+func roundTrip(msg slack.Msg) (slack.Msg, error) {
+	var decoded slack.Msg
+	raw, err := json.Marshal(msg)
+	if err != nil {
+		return decoded, fmt.Errorf("failed to marshal message: %w", err)
+	}
+	err = json.Unmarshal(raw, &decoded)
+	if err != nil {
+		return decoded, fmt.Errorf("failed to unmarshal message: %w", err)
+	}
+	return decoded, nil
+}
+
+// findAction looks for the element with the given action ID in the blocks and builds the action Slack would send
+func findAction(blocks slack.Blocks, actionID, value string) (*slack.BlockAction, error) {
+	raw, err := json.Marshal(blocks)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal blocks: %w", err)
+	}
+	var generic []map[string]any
+	err = json.Unmarshal(raw, &generic)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal blocks: %w", err)
+	}
+
+	for _, block := range generic {
+		element := findElement(block, actionID)
+		if element == nil {
+			continue
+		}
+		blockID, _ := block["block_id"].(string)
+		elementType, _ := element["type"].(string)
+		return &slack.BlockAction{
+			ActionID: actionID,
+			BlockID:  blockID,
+			Type:     slack.ActionType(elementType),
+			Value:    value,
+		}, nil
+	}
+	return nil, fmt.Errorf("action %q not found", actionID)
+}
+
+func findElement(node any, actionID string) map[string]any {
+	switch node := node.(type) {
+	case map[string]any:
+		if node["action_id"] == actionID {
+			return node
+		}
+		for _, child := range node {
+			found := findElement(child, actionID)
+			if found != nil {
+				return found
+			}
+		}
+	case []any:
+		for _, child := range node {
+			found := findElement(child, actionID)
+			if found != nil {
+				return found
+			}
+		}
+	}
+	return nil
+}
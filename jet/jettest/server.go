@@ -0,0 +1,335 @@
+package jettest
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/slack-go/slack"
+)
+
+const (
+	DefaultTeamID    = "T00000001"
+	DefaultUserID    = "U00000001"
+	DefaultChannelID = "C00000001"
+	DefaultToken     = "xoxb-jettest"
+)
+
+// Message is a message as stored by the fake Slack server.
+type Message struct {
+	slack.Msg
+	ChannelID string
+}
+
+// View is a modal or a home tab as stored by the fake Slack server.
+type View struct {
+	slack.View
+	UserID    string
+	TriggerID string
+}
+
+// Dialog is a legacy dialog opened in the fake Slack server.
+type Dialog struct {
+	slack.Dialog
+	UserID    string
+	TriggerID string
+}
+
+// Response is a payload which was sent to a `response_url`.
+type Response struct {
+	URL string
+	slack.Msg
+}
+
+// FunctionResult is how a workflow function execution was completed.
+type FunctionResult struct {
+	ExecutionID string
+	Outputs     map[string]string
+	// empty if it succeeded
+	Error string
+}
+
+type responseTarget struct {
+	channelID string
+	messageTS string
+}
+
+// Server is an in-process fake of the Slack Web API, it only implements the methods used by jet.
+type Server struct {
+	TeamID    string
+	UserID    string
+	ChannelID string
+
+	server *httptest.Server
+
+	lock      sync.Mutex
+	epoch     int64
+	seq       int
+	messages  map[string][]*Message
+	views     map[string]*View
+	homes     map[string]*View
+	triggers  map[string]string
+	targets   map[string]responseTarget
+	responses []Response
+	functions map[string]FunctionResult
+	dialogs   []Dialog
+}
+
+// NewServer starts a fake Slack server, it must be closed with Close once done.
+func NewServer() *Server {
+	me := &Server{
+		TeamID:    DefaultTeamID,
+		UserID:    DefaultUserID,
+		ChannelID: DefaultChannelID,
+		epoch:     time.Now().Unix(),
+		messages:  make(map[string][]*Message),
+		views:     make(map[string]*View),
+		homes:     make(map[string]*View),
+		triggers:  make(map[string]string),
+		targets:   make(map[string]responseTarget),
+		functions: make(map[string]FunctionResult),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /api/chat.postMessage", me.withToken(me.postMessage))
+	mux.HandleFunc("POST /api/chat.update", me.withToken(me.updateMessage))
+	mux.HandleFunc("POST /api/conversations.history", me.withToken(me.conversationsHistory))
+	mux.HandleFunc("POST /api/views.open", me.withToken(me.openView))
+	mux.HandleFunc("POST /api/views.push", me.withToken(me.pushView))
+	mux.HandleFunc("POST /api/views.update", me.withToken(me.updateView))
+	mux.HandleFunc("POST /api/views.publish", me.withToken(me.publishView))
+	mux.HandleFunc("POST /api/dialog.open", me.withToken(me.openDialog))
+	mux.HandleFunc("POST /api/functions.completeSuccess", me.withToken(me.completeFunction))
+	mux.HandleFunc("POST /api/functions.completeError", me.withToken(me.completeFunction))
+	mux.HandleFunc("/api/", func(w http.ResponseWriter, r *http.Request) {
+		writeError(w, "unknown_method")
+	})
+	mux.HandleFunc("POST /response/{id}", me.respond)
+	me.server = httptest.NewServer(mux)
+	return me
+}
+
+func (me *Server) Close() {
+	me.server.Close()
+}
+
+// URL returns the base URL of the fake server.
+func (me *Server) URL() string {
+	return me.server.URL
+}
+
+// APIURL returns the URL to use as `jet.Options.APIURL`.
+func (me *Server) APIURL() string {
+	return me.server.URL + "/api/"
+}
+
+// Messages returns a snapshot of the messages of a channel, oldest first.
+func (me *Server) Messages(channelID string) []Message {
+	me.lock.Lock()
+	defer me.lock.Unlock()
+	messages := make([]Message, 0, len(me.messages[channelID]))
+	for _, msg := range me.messages[channelID] {
+		messages = append(messages, msg.copy())
+	}
+	return messages
+}
+
+// LastMessage returns the latest message of a channel, or nil if there is none.
+func (me *Server) LastMessage(channelID string) *Message {
+	messages := me.Messages(channelID)
+	if len(messages) == 0 {
+		return nil
+	}
+	return &messages[len(messages)-1]
+}
+
+// Message returns a message by its timestamp, or nil if it doesn't exist.
+func (me *Server) Message(channelID, ts string) *Message {
+	me.lock.Lock()
+	defer me.lock.Unlock()
+	_, msg := me.findMessage(channelID, ts)
+	if msg == nil {
+		return nil
+	}
+	copied := msg.copy()
+	return &copied
+}
+
+// Views returns a snapshot of the modals which were opened, in order.
+func (me *Server) Views() []View {
+	me.lock.Lock()
+	defer me.lock.Unlock()
+	views := make([]View, 0, len(me.views))
+	for _, view := range me.views {
+		views = append(views, *view)
+	}
+	slices.SortFunc(views, func(a, b View) int {
+		return strings.Compare(a.ID, b.ID)
+	})
+	return views
+}
+
+// View returns a modal by its ID, or nil if it doesn't exist.
+func (me *Server) View(viewID string) *View {
+	me.lock.Lock()
+	defer me.lock.Unlock()
+	view, found := me.views[viewID]
+	if !found {
+		return nil
+	}
+	copied := *view
+	return &copied
+}
+
+// HomeView returns the home tab published for a user, or nil if there is none.
+func (me *Server) HomeView(userID string) *View {
+	me.lock.Lock()
+	defer me.lock.Unlock()
+	view, found := me.homes[userID]
+	if !found {
+		return nil
+	}
+	copied := *view
+	return &copied
+}
+
+// Responses returns every payload sent to a `response_url`, in order.
+func (me *Server) Responses() []Response {
+	me.lock.Lock()
+	defer me.lock.Unlock()
+	return slices.Clone(me.responses)
+}
+
+// Dialogs returns every legacy dialog which was opened, in order.
+func (me *Server) Dialogs() []Dialog {
+	me.lock.Lock()
+	defer me.lock.Unlock()
+	return slices.Clone(me.dialogs)
+}
+
+// FunctionResult returns how the workflow function execution was completed, nil if it is still pending.
+func (me *Server) FunctionResult(executionID string) *FunctionResult {
+	me.lock.Lock()
+	defer me.lock.Unlock()
+	result, found := me.functions[executionID]
+	if !found {
+		return nil
+	}
+	return &result
+}
+
+// NewTriggerID creates a trigger ID for the given user, which can then be used to open a modal.
+func (me *Server) NewTriggerID(userID string) string {
+	me.lock.Lock()
+	defer me.lock.Unlock()
+	trigger := fmt.Sprintf("%d.%s", me.nextSeq(), userID)
+	me.triggers[trigger] = userID
+	return trigger
+}
+
+// NewResponseURL creates a `response_url` bound to a channel and optionally to a message (which is replaced/deleted when requested).
+func (me *Server) NewResponseURL(channelID, messageTS string) string {
+	me.lock.Lock()
+	defer me.lock.Unlock()
+	id := strconv.Itoa(me.nextSeq())
+	me.targets[id] = responseTarget{
+		channelID: channelID,
+		messageTS: messageTS,
+	}
+	return me.server.URL + "/response/" + id
+}
+
+// AddMessage stores a message as if it had been posted to the channel, its timestamp is generated if empty.
+func (me *Server) AddMessage(channelID string, msg slack.Msg) Message {
+	me.lock.Lock()
+	defer me.lock.Unlock()
+	return *me.addMessage(channelID, msg)
+}
+
+func (me *Server) nextID() int {
+	me.lock.Lock()
+	defer me.lock.Unlock()
+	return me.nextSeq()
+}
+
+func (me *Server) nextSeq() int {
+	me.seq += 1
+	return me.seq
+}
+
+func (me *Server) newTS() string {
+	return fmt.Sprintf("%d.%06d", me.epoch, me.nextSeq())
+}
+
+func (me *Server) addMessage(channelID string, msg slack.Msg) *Message {
+	if msg.Timestamp == "" {
+		msg.Timestamp = me.newTS()
+	}
+	msg.Channel = channelID
+	stored := &Message{
+		Msg:       msg,
+		ChannelID: channelID,
+	}
+	me.messages[channelID] = append(me.messages[channelID], stored)
+	return stored
+}
+
+// copy doesn't share the metadata with the stored message, so changing one doesn't affect the other (as with the real Slack)
+func (me *Message) copy() Message {
+	copied := *me
+	copied.Metadata.EventPayload = maps.Clone(me.Metadata.EventPayload)
+	return copied
+}
+
+func (me *Server) findMessage(channelID, ts string) (int, *Message) {
+	for i, msg := range me.messages[channelID] {
+		if msg.Timestamp == ts {
+			return i, msg
+		}
+	}
+	return -1, nil
+}
+
+func (me *Server) withToken(handler http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if token == "" {
+			err := r.ParseForm()
+			if err != nil {
+				writeError(w, "invalid_form_data")
+				return
+			}
+			token = r.PostForm.Get("token")
+		}
+		if token == "" {
+			writeError(w, "not_authed")
+			return
+		}
+		handler(w, r)
+	}
+}
+
+func writeJSON(w http.ResponseWriter, body map[string]any) {
+	body["ok"] = true
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+func writeError(w http.ResponseWriter, code string) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]any{
+		"ok":    false,
+		"error": code,
+	})
+}
+
+func decodeJSON(r *http.Request, into any) error {
+	return json.NewDecoder(r.Body).Decode(into)
+}
@@ -0,0 +1,174 @@
+package jettest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/slack-go/slack"
+)
+
+func newClient(srv *Server, token string) *slack.Client {
+	return slack.New(token, slack.OptionAPIURL(srv.APIURL()), slack.OptionHTTPClient(srv.server.Client()))
+}
+
+func TestMessages(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+	client := newClient(srv, DefaultToken)
+	ctx := context.Background()
+
+	metadata := slack.SlackMetadata{EventType: "jet", EventPayload: map[string]any{"key": "value"}}
+	channelID, ts, err := client.PostMessageContext(ctx, srv.ChannelID, slack.MsgOptionText("hello", false), slack.MsgOptionMetadata(metadata))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _, _, err = client.UpdateMessageContext(ctx, channelID, ts, slack.MsgOptionText("updated", false), slack.MsgOptionMetadata(metadata))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _, _, err = client.UpdateMessageContext(ctx, channelID, "1.000000", slack.MsgOptionText("updated", false))
+	if err == nil || err.Error() != "message_not_found" {
+		t.Errorf("expected message_not_found, got %v", err)
+	}
+
+	msg := srv.Message(channelID, ts)
+	if msg == nil || msg.Text != "updated" || msg.Metadata.EventPayload["key"] != "value" {
+		t.Fatalf("unexpected stored message: %+v", msg)
+	}
+	// the stored message can't be changed through what is returned
+	msg.Metadata.EventPayload["key"] = "changed"
+	if srv.Message(channelID, ts).Metadata.EventPayload["key"] != "value" {
+		t.Error("expected the stored metadata to be unchanged")
+	}
+
+	for _, withMetadata := range []bool{false, true} {
+		res, err := client.GetConversationHistoryContext(ctx, &slack.GetConversationHistoryParameters{
+			ChannelID:          channelID,
+			Latest:             ts,
+			Inclusive:          true,
+			Limit:              1,
+			IncludeAllMetadata: withMetadata,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(res.Messages) != 1 || res.Messages[0].Timestamp != ts {
+			t.Fatalf("expected the message, got %+v", res.Messages)
+		}
+		if hasMetadata := res.Messages[0].Metadata.EventType != ""; hasMetadata != withMetadata {
+			t.Errorf("expected metadata to be returned: %v, got %+v", withMetadata, res.Messages[0].Metadata)
+		}
+	}
+}
+
+func TestRequiresToken(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+
+	_, _, err := newClient(srv, "").PostMessageContext(context.Background(), srv.ChannelID, slack.MsgOptionText("hello", false))
+	if err == nil || err.Error() != "not_authed" {
+		t.Errorf("expected not_authed, got %v", err)
+	}
+	_, err = newClient(srv, DefaultToken).AuthTestContext(context.Background())
+	if err == nil || err.Error() != "unknown_method" {
+		t.Errorf("expected unknown_method, got %v", err)
+	}
+}
+
+func TestViews(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+	client := newClient(srv, DefaultToken)
+	ctx := context.Background()
+
+	modal := slack.ModalViewRequest{
+		Type:  slack.VTModal,
+		Title: slack.NewTextBlockObject(slack.PlainTextType, "Modal", false, false),
+	}
+	_, err := client.OpenViewContext(ctx, "", modal)
+	if err == nil {
+		t.Error("expected views.open to require a trigger ID")
+	}
+	opened, err := client.OpenViewContext(ctx, srv.NewTriggerID("U2"), modal)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pushed, err := client.PushViewContext(ctx, srv.NewTriggerID("U2"), modal)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pushed.View.RootViewID != opened.View.ID || pushed.View.PreviousViewID != opened.View.ID {
+		t.Errorf("expected the view to be pushed on top of %q, got %+v", opened.View.ID, pushed.View)
+	}
+
+	_, err = client.UpdateViewContext(ctx, modal, "", "stale", opened.View.ID)
+	if err == nil || err.Error() != "hash_conflict" {
+		t.Errorf("expected hash_conflict, got %v", err)
+	}
+	modal.Title = slack.NewTextBlockObject(slack.PlainTextType, "Updated", false, false)
+	_, err = client.UpdateViewContext(ctx, modal, "", opened.View.Hash, opened.View.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	view := srv.View(opened.View.ID)
+	if view == nil || view.UserID != "U2" || view.Title.Text != "Updated" {
+		t.Fatalf("unexpected stored view: %+v", view)
+	}
+	if len(srv.Views()) != 2 {
+		t.Errorf("expected 2 views, got %d", len(srv.Views()))
+	}
+
+	home := slack.HomeTabViewRequest{Type: slack.VTHomeTab}
+	published, err := client.PublishViewContext(ctx, "U2", home, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	republished, err := client.PublishViewContext(ctx, "U2", home, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if republished.View.ID != published.View.ID {
+		t.Errorf("expected the home tab to keep its ID, got %q and %q", published.View.ID, republished.View.ID)
+	}
+	if srv.HomeView("U2") == nil || srv.HomeView(srv.UserID) != nil {
+		t.Error("expected a home tab only for U2")
+	}
+}
+
+func TestResponseURL(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+	original := srv.AddMessage(srv.ChannelID, slack.Msg{Text: "original"})
+
+	respond := func(url string, msg slack.Msg) {
+		t.Helper()
+		body, err := json.Marshal(msg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		res, err := http.Post(url, "application/json", bytes.NewReader(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		res.Body.Close()
+	}
+
+	respond(srv.NewResponseURL(srv.ChannelID, original.Timestamp), slack.Msg{Text: "replaced", ReplaceOriginal: true})
+	if msg := srv.Message(srv.ChannelID, original.Timestamp); msg == nil || msg.Text != "replaced" {
+		t.Errorf("expected the message to be replaced, got %+v", msg)
+	}
+	respond(srv.NewResponseURL(srv.ChannelID, ""), slack.Msg{Text: "new"})
+	if msg := srv.LastMessage(srv.ChannelID); msg == nil || msg.Text != "new" || msg.Timestamp == original.Timestamp {
+		t.Errorf("expected a new message, got %+v", msg)
+	}
+	respond(srv.NewResponseURL(srv.ChannelID, original.Timestamp), slack.Msg{DeleteOriginal: true})
+	if msg := srv.Message(srv.ChannelID, original.Timestamp); msg != nil {
+		t.Errorf("expected the message to be deleted, got %+v", msg)
+	}
+	if len(srv.Responses()) != 3 {
+		t.Errorf("expected 3 responses, got %d", len(srv.Responses()))
+	}
+}
@@ -0,0 +1,45 @@
+package jettest
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/LouisBrunner/jet/jet"
+)
+
+// UpdateSnapshotsEnv is the environment variable which, when set to 1, rewrites the snapshots instead of comparing them.
+const UpdateSnapshotsEnv = "JET_UPDATE_SNAPSHOTS"
+
+// AssertSnapshot compares the Block Kit JSON of a render with the golden file `testdata/<name>.json`.
+// The file is created if it doesn't exist yet.
+func AssertSnapshot(t testing.TB, render *jet.TestRender, name string) {
+	t.Helper()
+
+	actual, err := render.BlocksJSON()
+	if err != nil {
+		t.Fatalf("failed to serialize blocks: %v", err)
+	}
+	actual = append(actual, '\n')
+
+	path := filepath.Join("testdata", name+".json")
+	expected, err := os.ReadFile(path)
+	if os.IsNotExist(err) || os.Getenv(UpdateSnapshotsEnv) == "1" {
+		err = os.MkdirAll(filepath.Dir(path), 0o755)
+		if err == nil {
+			err = os.WriteFile(path, actual, 0o644)
+		}
+		if err != nil {
+			t.Fatalf("failed to write snapshot %s: %v", path, err)
+		}
+		return
+	}
+	if err != nil {
+		t.Fatalf("failed to read snapshot %s: %v", path, err)
+	}
+
+	if !bytes.Equal(expected, actual) {
+		t.Errorf("snapshot %s doesn't match (set %s=1 to update it)\n--- expected\n%s\n--- actual\n%s", path, UpdateSnapshotsEnv, expected, actual)
+	}
+}
@@ -0,0 +1,114 @@
+package jettest
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/LouisBrunner/jet/jet"
+	"github.com/slack-go/slack"
+)
+
+var counter = jet.NewFlow("counter", func(ctx jet.RenderContext, props jet.FlowProps) (*jet.RenderedFlow, error) {
+	count, setCount, err := jet.UseState(ctx, 0)
+	if err != nil {
+		return nil, err
+	}
+	increment, err := jet.UseCallback(ctx, func(ctx context.Context, args slack.BlockAction) error {
+		return setCount(count + 1)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &jet.RenderedFlow{
+		Blocks: slack.Blocks{BlockSet: []slack.Block{
+			slack.NewSectionBlock(slack.NewTextBlockObject(slack.MarkdownType, fmt.Sprintf("Count: *%d*", count), false, false), nil, nil),
+			slack.NewActionBlock("actions", slack.NewButtonBlockElement(increment, "", slack.NewTextBlockObject(slack.PlainTextType, "Increment", false, false))),
+		}},
+	}, nil
+}, nil)
+
+func TestAssertSnapshot(t *testing.T) {
+	render, err := jet.RenderForTest(counter, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	AssertSnapshot(t, render, "counter")
+
+	clicked, err := render.Trigger(render.CallbackIDs[0], slack.BlockAction{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	AssertSnapshot(t, clicked, "counter_clicked")
+}
+
+// recordingTB captures the failures instead of failing the test
+type recordingTB struct {
+	testing.TB
+	failures []string
+}
+
+func (me *recordingTB) Helper() {}
+
+func (me *recordingTB) Errorf(format string, args ...any) {
+	me.failures = append(me.failures, fmt.Sprintf(format, args...))
+}
+
+func (me *recordingTB) Fatalf(format string, args ...any) {
+	me.failures = append(me.failures, fmt.Sprintf(format, args...))
+}
+
+func TestAssertSnapshotLifecycle(t *testing.T) {
+	t.Chdir(t.TempDir())
+	path := filepath.Join("testdata", "counter.json")
+
+	render, err := jet.RenderForTest(counter, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	clicked, err := render.Trigger(render.CallbackIDs[0], slack.BlockAction{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected, err := render.BlocksJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	updated, err := clicked.BlocksJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// missing snapshots are created
+	tb := &recordingTB{TB: t}
+	AssertSnapshot(tb, render, "counter")
+	written, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("expected the snapshot to be created: %v", err)
+	}
+	if string(written) != string(expected)+"\n" || len(tb.failures) != 0 {
+		t.Fatalf("unexpected snapshot %s (failures: %v)", written, tb.failures)
+	}
+
+	// a different render fails without touching the snapshot
+	AssertSnapshot(tb, clicked, "counter")
+	if len(tb.failures) != 1 || !strings.Contains(tb.failures[0], "doesn't match") {
+		t.Fatalf("expected a mismatch, got %v", tb.failures)
+	}
+	written, err = os.ReadFile(path)
+	if err != nil || string(written) != string(expected)+"\n" {
+		t.Fatalf("expected the snapshot to be unchanged, got %s (%v)", written, err)
+	}
+
+	// the snapshot is rewritten when asked to
+	t.Setenv(UpdateSnapshotsEnv, "1")
+	tb = &recordingTB{TB: t}
+	AssertSnapshot(tb, clicked, "counter")
+	written, err = os.ReadFile(path)
+	if err != nil || string(written) != string(updated)+"\n" || len(tb.failures) != 0 {
+		t.Fatalf("expected the snapshot to be updated, got %s (%v, failures: %v)", written, err, tb.failures)
+	}
+}
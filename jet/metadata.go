@@ -1,25 +1,44 @@
 package jet
 
 import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"maps"
+	"time"
 
 	"github.com/slack-go/slack"
 )
 
 const (
 	jetMetadataEntry = "__jet"
+	// Slack rejects views with a longer private_metadata
+	maxViewMetadataSize = 3000
+	// Slack's limit for the metadata of messages
+	defaultMessageMetadataLimit = 8000
+
+	defaultStateStoreGracePeriod = 15 * time.Minute
 )
 
+var ErrMetadataTooLarge = errors.New("metadata too large")
+
 type slackMetadataJet struct {
 	Flow  string              `json:"f" mapstructure:"f"`
 	Hooks []slackMetadataHook `json:"h,omitempty" mapstructure:"h"`
 	Props FlowProps           `json:"p,omitempty" mapstructure:"p"`
+	// when the rest is kept in the StateStore
+	Ref string `json:"r,omitempty" mapstructure:"r"`
+	// when using the compact encoding, replaces Hooks and Props
+	Version int    `json:"v,omitempty" mapstructure:"v"`
+	Compact string `json:"c,omitempty" mapstructure:"c"`
 
 	Original slack.SlackMetadata `json:"-"`
 }
 
-func deserializeMetadata(meta *slack.SlackMetadata, privMeta string) (*slackMetadataJet, error) {
+func deserializeMetadata(meta *slack.SlackMetadata, privMeta string, secret *MetadataSecret) (*slackMetadataJet, error) {
 	jetEntryRaw, found := meta.EventPayload[jetMetadataEntry]
 	if !found {
 		if privMeta == "" {
@@ -37,6 +56,10 @@ func deserializeMetadata(meta *slack.SlackMetadata, privMeta string) (*slackMeta
 		}
 		meta = &wrappedMeta
 	}
+	jetEntryRaw, err := openMetadataEntry(jetEntryRaw, secret)
+	if err != nil {
+		return nil, err
+	}
 	jetEntryJSON, err := json.Marshal(jetEntryRaw)
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal jet metadata: %w", err)
@@ -46,24 +69,226 @@ func deserializeMetadata(meta *slack.SlackMetadata, privMeta string) (*slackMeta
 	if err != nil {
 		return nil, fmt.Errorf("failed to unmarshal jet metadata: %w", err)
 	}
+	err = expandMetadata(&jetEntry)
+	if err != nil {
+		return nil, err
+	}
 	jetEntry.Original = *meta
 	return &jetEntry, nil
 }
 
-func serializeMetadata(prev *slack.SlackMetadata, name string, rctx *renderContext) slack.SlackMetadata {
+// expandMetadata decodes the hooks and props of an entry using a compact encoding, wherever it was stored
+func expandMetadata(jetEntry *slackMetadataJet) error {
+	switch jetEntry.Version {
+	case 0:
+		return nil
+	case compactMetadataVersion:
+		var err error
+		jetEntry.Hooks, jetEntry.Props, err = decodeCompactMetadata(jetEntry.Flow, jetEntry.Compact)
+		if err != nil {
+			return err
+		}
+		jetEntry.Version = 0
+		jetEntry.Compact = ""
+		return nil
+	default:
+		return fmt.Errorf("unsupported jet metadata version: %d", jetEntry.Version)
+	}
+}
+
+// openMetadataEntry verifies the jet metadata coming from Slack when a MetadataSecret is used
+func openMetadataEntry(raw interface{}, secret *MetadataSecret) (interface{}, error) {
+	switch entry := raw.(type) {
+	case slackMetadataJet:
+		// rendered by this process, never went through Slack
+		return entry, nil
+	case string:
+		if secret == nil {
+			return nil, fmt.Errorf("%w: metadata is sealed but no MetadataSecret is configured", ErrInvalidMetadata)
+		}
+		data, err := secret.open(entry)
+		if err != nil {
+			return nil, err
+		}
+		return json.RawMessage(data), nil
+	default:
+		if secret != nil {
+			return nil, fmt.Errorf("%w: metadata is not sealed", ErrInvalidMetadata)
+		}
+		return entry, nil
+	}
+}
+
+func serializeMetadata(prev *slack.SlackMetadata, name string, rctx *renderContext, compact bool) (slack.SlackMetadata, error) {
 	meta := slackMetadataJet{
 		Flow:  name,
 		Hooks: rctx.serializeHooks(),
 		Props: rctx.props,
 	}
+	if compact {
+		encoded, err := encodeCompactMetadata(name, meta.Hooks, meta.Props)
+		if err != nil {
+			return slack.SlackMetadata{}, err
+		}
+		meta = slackMetadataJet{
+			Flow:    name,
+			Version: compactMetadataVersion,
+			Compact: encoded,
+		}
+	}
 	if prev != nil {
 		prev.EventPayload[jetMetadataEntry] = meta
-		return *prev
+		return *prev, nil
 	}
 	return slack.SlackMetadata{
 		EventType: "jet",
 		EventPayload: map[string]interface{}{
 			jetMetadataEntry: meta,
 		},
+	}, nil
+}
+
+// decodeMetadata deserializes the metadata and fetches the state from the StateStore if needed
+func (me *app) decodeMetadata(ctx context.Context, meta *slack.SlackMetadata, privMeta string) (*slackMetadataJet, error) {
+	jetEntry, err := deserializeMetadata(meta, privMeta, me.opts.MetadataSecret)
+	if err != nil {
+		return nil, err
+	}
+	if jetEntry.Ref == "" {
+		return jetEntry, nil
+	}
+
+	if me.opts.StateStore == nil {
+		return nil, fmt.Errorf("jet metadata refers to stored state %q but no StateStore is configured", jetEntry.Ref)
+	}
+	data, err := me.opts.StateStore.Get(ctx, jetEntry.Ref)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get stored state %q: %w", jetEntry.Ref, err)
+	}
+	var stored slackMetadataJet
+	err = json.Unmarshal(data, &stored)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal stored state %q: %w", jetEntry.Ref, err)
+	}
+	err = expandMetadata(&stored)
+	if err != nil {
+		return nil, err
+	}
+	stored.Ref = jetEntry.Ref
+	stored.Original = jetEntry.Original
+	return &stored, nil
+}
+
+// encodeMetadata seals the jet metadata and moves it to the StateStore if it is too large (or if configured to do so)
+func (me *app) encodeMetadata(ctx context.Context, meta slack.SlackMetadata, limit int) (slack.SlackMetadata, error) {
+	entry, found := meta.EventPayload[jetMetadataEntry]
+	if !found {
+		return meta, nil
+	}
+
+	withEntry := func(entry interface{}) (slack.SlackMetadata, bool, int, error) {
+		sealed, err := me.sealMetadataEntry(entry)
+		if err != nil {
+			return meta, false, 0, err
+		}
+		encoded := meta
+		encoded.EventPayload = maps.Clone(meta.EventPayload)
+		encoded.EventPayload[jetMetadataEntry] = sealed
+		if limit <= 0 {
+			return encoded, true, 0, nil
+		}
+		raw, err := json.Marshal(encoded)
+		if err != nil {
+			return meta, false, 0, fmt.Errorf("failed to marshal metadata: %w", err)
+		}
+		return encoded, len(raw) <= limit, len(raw), nil
+	}
+
+	encoded, fits, size, err := withEntry(entry)
+	if err != nil {
+		return meta, err
+	}
+	store := me.opts.StateStore
+	if fits && (store == nil || !me.opts.AlwaysUseStateStore) {
+		return encoded, nil
+	}
+	if store == nil {
+		return meta, fmt.Errorf("%w: %d > %d characters, use a StateStore to keep the state outside of Slack", ErrMetadataTooLarge, size, limit)
+	}
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return meta, fmt.Errorf("failed to marshal jet metadata: %w", err)
+	}
+	key, err := newStateKey()
+	if err != nil {
+		return meta, err
+	}
+	err = store.Put(ctx, key, data)
+	if err != nil {
+		return meta, fmt.Errorf("failed to store state: %w", err)
+	}
+
+	ref := slackMetadataJet{
+		Ref: key,
+	}
+	if jetEntry, ok := entry.(slackMetadataJet); ok {
+		ref.Flow = jetEntry.Flow
+	}
+	encoded, fits, size, err = withEntry(ref)
+	if err != nil {
+		return meta, err
+	}
+	if !fits {
+		return meta, fmt.Errorf("%w: %d > %d characters even after moving the state to the StateStore", ErrMetadataTooLarge, size, limit)
+	}
+	return encoded, nil
+}
+
+func (me *app) sealMetadataEntry(entry interface{}) (interface{}, error) {
+	if me.opts.MetadataSecret == nil {
+		return entry, nil
+	}
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal jet metadata: %w", err)
+	}
+	return me.opts.MetadataSecret.seal(data)
+}
+
+func (me *app) messageMetadataLimit() int {
+	if me.opts.MessageMetadataLimit != 0 {
+		return me.opts.MessageMetadataLimit
+	}
+	return defaultMessageMetadataLimit
+}
+
+func (me *app) stateStoreGracePeriod() time.Duration {
+	if me.opts.StateStoreGracePeriod > 0 {
+		return me.opts.StateStoreGracePeriod
+	}
+	return defaultStateStoreGracePeriod
+}
+
+// releaseMetadata removes the previous state from the StateStore once it has been replaced.
+// The deletion is delayed as Slack can still send the previous metadata for a while (e.g. clicks before the message is refreshed, async updates).
+func (me *app) releaseMetadata(meta *slackMetadataJet) {
+	if meta.Ref == "" || me.opts.StateStore == nil {
+		return
+	}
+	time.AfterFunc(me.stateStoreGracePeriod(), func() {
+		err := me.opts.StateStore.Delete(context.Background(), meta.Ref)
+		if err != nil {
+			me.LogErrorf("failed to delete stored state %q: %v", meta.Ref, err)
+		}
+	})
+}
+
+func newStateKey() (string, error) {
+	key := make([]byte, 16)
+	_, err := rand.Read(key)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate state key: %w", err)
 	}
+	return hex.EncodeToString(key), nil
 }
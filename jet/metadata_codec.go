@@ -0,0 +1,163 @@
+package jet
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+)
+
+// compact encoding of the hooks and props, enabled with FlowOptions.CompactMetadata
+// the version is stored alongside so that older messages can still be decoded if the format changes
+const (
+	compactMetadataVersion = 1
+
+	compactPlainPrefix = "j"
+	compactGzipPrefix  = "z"
+
+	// bounds the decompressed payload, well above what fits in message metadata or a sensible stored state,
+	// so that a crafted payload can't exhaust the memory when it is inflated
+	maxCompactMetadataSize = 1 << 20
+)
+
+// the index in this list is what gets stored, only append to it
+var compactHookKinds = []string{
+	hookState,
+	hookCallback,
+	hookEffectStart,
+	hookOptions,
+	hookClose,
+}
+
+var compactEncoding = base64.RawStdEncoding
+
+// each hook is stored as `[kind, data?, callbackID?]`, the callback ID is dropped when it can be rebuilt from the flow name and index
+func encodeCompactMetadata(flow string, hooks []slackMetadataHook, props FlowProps) (string, error) {
+	entries := make([][]interface{}, len(hooks))
+	for i, hook := range hooks {
+		kind := slices.Index(compactHookKinds, hook.Kind)
+		if kind < 0 {
+			return "", fmt.Errorf("unknown hook kind: %s", hook.Kind)
+		}
+		entry := []interface{}{kind}
+		callbackID := ""
+		if hook.CallbackID != defaultCallbackID(flow, hook.Kind, i) {
+			callbackID = hook.CallbackID
+		}
+		if hook.Data != nil || callbackID != "" {
+			entry = append(entry, hook.Data)
+		}
+		if callbackID != "" {
+			entry = append(entry, callbackID)
+		}
+		entries[i] = entry
+	}
+
+	payload, err := json.Marshal([]interface{}{entries, props})
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal compact metadata: %w", err)
+	}
+	if len(payload) > maxCompactMetadataSize {
+		return "", fmt.Errorf("compact metadata is larger than %d bytes", maxCompactMetadataSize)
+	}
+
+	plain := compactPlainPrefix + string(payload)
+	var compressed bytes.Buffer
+	writer := gzip.NewWriter(&compressed)
+	_, err = writer.Write(payload)
+	if err != nil {
+		return "", fmt.Errorf("failed to compress metadata: %w", err)
+	}
+	err = writer.Close()
+	if err != nil {
+		return "", fmt.Errorf("failed to compress metadata: %w", err)
+	}
+	zipped := compactGzipPrefix + compactEncoding.EncodeToString(compressed.Bytes())
+
+	// the plain version is escaped once embedded in JSON, so compare the final sizes
+	plainJSON, err := json.Marshal(plain)
+	if err != nil {
+		return "", err
+	}
+	if len(zipped) < len(plainJSON) {
+		return zipped, nil
+	}
+	return plain, nil
+}
+
+func decodeCompactMetadata(flow string, encoded string) ([]slackMetadataHook, FlowProps, error) {
+	if encoded == "" {
+		return nil, nil, fmt.Errorf("empty compact metadata")
+	}
+
+	var payload []byte
+	switch encoded[:1] {
+	case compactPlainPrefix:
+		payload = []byte(encoded[1:])
+	case compactGzipPrefix:
+		compressed, err := compactEncoding.DecodeString(encoded[1:])
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to decode compact metadata: %w", err)
+		}
+		reader, err := gzip.NewReader(bytes.NewReader(compressed))
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to decompress compact metadata: %w", err)
+		}
+		payload, err = io.ReadAll(io.LimitReader(reader, maxCompactMetadataSize+1))
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to decompress compact metadata: %w", err)
+		}
+		if len(payload) > maxCompactMetadataSize {
+			return nil, nil, fmt.Errorf("compact metadata is larger than %d bytes once decompressed", maxCompactMetadataSize)
+		}
+	default:
+		return nil, nil, fmt.Errorf("unknown compact metadata format: %q", encoded[:1])
+	}
+
+	var parts []json.RawMessage
+	err := json.Unmarshal(payload, &parts)
+	if err != nil || len(parts) != 2 {
+		return nil, nil, fmt.Errorf("invalid compact metadata: %v", err)
+	}
+	var entries [][]json.RawMessage
+	err = json.Unmarshal(parts[0], &entries)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid compact hooks: %w", err)
+	}
+	var props FlowProps
+	err = json.Unmarshal(parts[1], &props)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid compact props: %w", err)
+	}
+
+	hooks := make([]slackMetadataHook, len(entries))
+	for i, entry := range entries {
+		if len(entry) == 0 {
+			return nil, nil, fmt.Errorf("invalid compact hook %d", i)
+		}
+		var kind int
+		err = json.Unmarshal(entry[0], &kind)
+		if err != nil || kind < 0 || kind >= len(compactHookKinds) {
+			return nil, nil, fmt.Errorf("invalid compact hook kind %d: %s", i, entry[0])
+		}
+		hook := slackMetadataHook{
+			Kind: compactHookKinds[kind],
+		}
+		hook.CallbackID = defaultCallbackID(flow, hook.Kind, i)
+		// a null data is only a placeholder when followed by a callback ID, otherwise it's the actual value (e.g. a nil slice)
+		if len(entry) == 2 || (len(entry) > 2 && string(entry[1]) != "null") {
+			hook.Data = entry[1]
+		}
+		if len(entry) > 2 {
+			err = json.Unmarshal(entry[2], &hook.CallbackID)
+			if err != nil {
+				return nil, nil, fmt.Errorf("invalid compact callback ID %d: %w", i, err)
+			}
+		}
+		hooks[i] = hook
+	}
+	return hooks, props, nil
+}
@@ -0,0 +1,82 @@
+package jet
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCompactMetadataRoundTrip(t *testing.T) {
+	hooks := []slackMetadataHook{
+		{Kind: hookState, Data: json.RawMessage(`{"count":3}`)},
+		{Kind: hookState, Data: json.RawMessage(`null`)},
+		{Kind: hookCallback, CallbackID: defaultCallbackID("counter", hookCallback, 2)},
+		{Kind: hookCallback, CallbackID: "custom"},
+	}
+	props := FlowProps{"user": "U123"}
+	tests := []struct {
+		name   string
+		props  FlowProps
+		prefix string
+	}{
+		{name: "plain", props: props, prefix: compactPlainPrefix},
+		{name: "gzip", props: FlowProps{"text": strings.Repeat("repeated ", 200)}, prefix: compactGzipPrefix},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			encoded, err := encodeCompactMetadata("counter", hooks, test.props)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.HasPrefix(encoded, test.prefix) {
+				t.Fatalf("expected prefix %q, got %q", test.prefix, encoded[:1])
+			}
+			decodedHooks, decodedProps, err := decodeCompactMetadata("counter", encoded)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(decodedHooks, hooks) {
+				t.Errorf("expected hooks %+v, got %+v", hooks, decodedHooks)
+			}
+			if !reflect.DeepEqual(decodedProps, test.props) {
+				t.Errorf("expected props %+v, got %+v", test.props, decodedProps)
+			}
+		})
+	}
+}
+
+func TestCompactMetadataInvalid(t *testing.T) {
+	// valid once decompressed, only its size is wrong
+	var bomb bytes.Buffer
+	writer := gzip.NewWriter(&bomb)
+	_, err := writer.Write([]byte("[[]," + strings.Repeat(" ", maxCompactMetadataSize) + "{}]"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = writer.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		encoded string
+	}{
+		{name: "empty", encoded: ""},
+		{name: "unknown format", encoded: "x[]"},
+		{name: "invalid JSON", encoded: "j[[]"},
+		{name: "invalid base64", encoded: "z!!"},
+		{name: "too large once decompressed", encoded: compactGzipPrefix + compactEncoding.EncodeToString(bomb.Bytes())},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, _, err := decodeCompactMetadata("counter", test.encoded)
+			if err == nil {
+				t.Fatal("expected an error")
+			}
+		})
+	}
+}
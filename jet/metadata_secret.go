@@ -0,0 +1,117 @@
+package jet
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var ErrInvalidMetadata = errors.New("invalid jet metadata")
+
+type MetadataSecret struct {
+	// The keys used to protect the metadata.
+	// The first one is used for new metadata while all of them are accepted when reading it back,
+	// which allows rotating keys without breaking existing messages.
+	Keys [][]byte
+	// Encrypt the metadata (AES-GCM) instead of only signing it (HMAC-SHA256).
+	// This also hides the state and props from anyone who can read the message metadata.
+	Encrypt bool
+}
+
+const (
+	sealedSignedPrefix    = "s1."
+	sealedEncryptedPrefix = "e1."
+)
+
+var sealEncoding = base64.RawURLEncoding
+
+func (me *MetadataSecret) seal(data []byte) (string, error) {
+	if len(me.Keys) == 0 {
+		return "", errors.New("missing MetadataSecret keys")
+	}
+	key := me.Keys[0]
+
+	if !me.Encrypt {
+		payload := sealedSignedPrefix + sealEncoding.EncodeToString(data)
+		return payload + "." + sealEncoding.EncodeToString(signMetadata(key, payload)), nil
+	}
+
+	aead, err := metadataCipher(key)
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	_, err = rand.Read(nonce)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	sealed := aead.Seal(nonce, nonce, data, []byte(sealedEncryptedPrefix))
+	return sealedEncryptedPrefix + sealEncoding.EncodeToString(sealed), nil
+}
+
+// open accepts both formats, so that toggling Encrypt doesn't break existing messages
+func (me *MetadataSecret) open(sealed string) ([]byte, error) {
+	switch {
+	case strings.HasPrefix(sealed, sealedSignedPrefix):
+		payload, signature, found := strings.Cut(sealed[len(sealedSignedPrefix):], ".")
+		if !found {
+			return nil, fmt.Errorf("%w: malformed signature", ErrInvalidMetadata)
+		}
+		mac, err := sealEncoding.DecodeString(signature)
+		if err != nil {
+			return nil, fmt.Errorf("%w: malformed signature", ErrInvalidMetadata)
+		}
+		for _, key := range me.Keys {
+			if hmac.Equal(mac, signMetadata(key, sealedSignedPrefix+payload)) {
+				return sealEncoding.DecodeString(payload)
+			}
+		}
+		return nil, fmt.Errorf("%w: bad signature", ErrInvalidMetadata)
+
+	case strings.HasPrefix(sealed, sealedEncryptedPrefix):
+		data, err := sealEncoding.DecodeString(sealed[len(sealedEncryptedPrefix):])
+		if err != nil {
+			return nil, fmt.Errorf("%w: malformed payload", ErrInvalidMetadata)
+		}
+		for _, key := range me.Keys {
+			aead, err := metadataCipher(key)
+			if err != nil {
+				return nil, err
+			}
+			if len(data) < aead.NonceSize() {
+				return nil, fmt.Errorf("%w: malformed payload", ErrInvalidMetadata)
+			}
+			nonce, ciphertext := data[:aead.NonceSize()], data[aead.NonceSize():]
+			plain, err := aead.Open(nil, nonce, ciphertext, []byte(sealedEncryptedPrefix))
+			if err == nil {
+				return plain, nil
+			}
+		}
+		return nil, fmt.Errorf("%w: cannot decrypt", ErrInvalidMetadata)
+
+	default:
+		return nil, fmt.Errorf("%w: unknown format", ErrInvalidMetadata)
+	}
+}
+
+func signMetadata(key []byte, payload string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(payload))
+	return mac.Sum(nil)
+}
+
+// metadataCipher derives an AES-256 key so that secrets of any length can be used
+func metadataCipher(key []byte) (cipher.AEAD, error) {
+	derived := sha256.Sum256(key)
+	block, err := aes.NewCipher(derived[:])
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,123 @@
+package jet
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/slack-go/slack"
+)
+
+func TestMetadataSecretRoundTrip(t *testing.T) {
+	data := []byte(`{"f":"counter","h":[{"k":"state","d":1}]}`)
+	for _, encrypt := range []bool{false, true} {
+		secret := &MetadataSecret{Keys: [][]byte{[]byte("current")}, Encrypt: encrypt}
+		sealed, err := secret.seal(data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if encrypt && strings.Contains(sealed, "counter") {
+			t.Errorf("expected the encrypted metadata to hide its content, got %q", sealed)
+		}
+		opened, err := secret.open(sealed)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(opened, data) {
+			t.Errorf("expected %s, got %s", data, opened)
+		}
+	}
+}
+
+func TestMetadataSecretRotation(t *testing.T) {
+	data := []byte(`{"f":"counter"}`)
+	for _, encrypt := range []bool{false, true} {
+		previous := &MetadataSecret{Keys: [][]byte{[]byte("previous")}, Encrypt: encrypt}
+		sealed, err := previous.seal(data)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		rotated := &MetadataSecret{Keys: [][]byte{[]byte("current"), []byte("previous")}, Encrypt: !encrypt}
+		_, err = rotated.open(sealed)
+		if err != nil {
+			t.Errorf("expected metadata sealed with a previous key to be accepted: %v", err)
+		}
+
+		dropped := &MetadataSecret{Keys: [][]byte{[]byte("current")}, Encrypt: encrypt}
+		_, err = dropped.open(sealed)
+		if !errors.Is(err, ErrInvalidMetadata) {
+			t.Errorf("expected ErrInvalidMetadata once the key is dropped, got %v", err)
+		}
+	}
+}
+
+func TestMetadataSecretRejectsTampering(t *testing.T) {
+	secret := &MetadataSecret{Keys: [][]byte{[]byte("current")}}
+	sealed, err := secret.seal([]byte(`{"f":"counter"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	payload, signature, _ := strings.Cut(sealed[len(sealedSignedPrefix):], ".")
+	forged := sealedSignedPrefix + sealEncoding.EncodeToString([]byte(`{"f":"admin"}`)) + "." + signature
+
+	tests := []struct {
+		name   string
+		sealed string
+	}{
+		{name: "forged payload", sealed: forged},
+		{name: "missing signature", sealed: sealedSignedPrefix + payload},
+		{name: "malformed encrypted payload", sealed: sealedEncryptedPrefix + "AAAA"},
+		{name: "unknown format", sealed: "x1.abc"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := secret.open(test.sealed)
+			if !errors.Is(err, ErrInvalidMetadata) {
+				t.Errorf("expected ErrInvalidMetadata, got %v", err)
+			}
+		})
+	}
+}
+
+func TestDeserializeMetadataWithSecret(t *testing.T) {
+	secret := &MetadataSecret{Keys: [][]byte{[]byte("current")}}
+	sealed, err := secret.seal([]byte(`{"f":"counter"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	unsealed := map[string]interface{}{"f": "counter"}
+
+	tests := []struct {
+		name   string
+		entry  interface{}
+		secret *MetadataSecret
+		valid  bool
+	}{
+		{name: "sealed", entry: sealed, secret: secret, valid: true},
+		{name: "unsealed without secret", entry: unsealed, valid: true},
+		{name: "unsealed with secret", entry: unsealed, secret: secret},
+		{name: "sealed without secret", entry: sealed},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			meta, err := deserializeMetadata(&slack.SlackMetadata{
+				EventType:    "jet",
+				EventPayload: map[string]interface{}{jetMetadataEntry: test.entry},
+			}, "", test.secret)
+			if !test.valid {
+				if !errors.Is(err, ErrInvalidMetadata) {
+					t.Fatalf("expected ErrInvalidMetadata, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if meta.Flow != "counter" {
+				t.Errorf("expected flow %q, got %q", "counter", meta.Flow)
+			}
+		})
+	}
+}
@@ -0,0 +1,424 @@
+package jet_test
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/LouisBrunner/jet/jet"
+	"github.com/LouisBrunner/jet/jet/jettest"
+	"github.com/slack-go/slack"
+)
+
+// counterFlow increments a counter every time its button is clicked
+func counterFlow(name string, opts *jet.FlowOptions) jet.Flow {
+	return jet.NewFlow(name, func(ctx jet.RenderContext, props jet.FlowProps) (*jet.RenderedFlow, error) {
+		count, setCount, err := jet.UseState(ctx, 0)
+		if err != nil {
+			return nil, err
+		}
+		increment, err := jet.UseCallback(ctx, func(ctx context.Context, args slack.BlockAction) error {
+			return setCount(count + 1)
+		})
+		if err != nil {
+			return nil, err
+		}
+		return &jet.RenderedFlow{
+			Text: fmt.Sprintf("count: %d", count),
+			Blocks: slack.Blocks{BlockSet: []slack.Block{
+				button(increment, "Increment"),
+			}},
+		}, nil
+	}, opts)
+}
+
+func TestCounterMetadataRoundTrip(t *testing.T) {
+	tests := []struct {
+		name string
+		opts jet.Options
+		flow *jet.FlowOptions
+	}{
+		{
+			name: "plain",
+		},
+		{
+			name: "compact",
+			flow: &jet.FlowOptions{CompactMetadata: true},
+		},
+		{
+			name: "sealed",
+			opts: jet.Options{MetadataSecret: &jet.MetadataSecret{Keys: [][]byte{[]byte("0123456789abcdef0123456789abcdef")}, Encrypt: true}},
+		},
+		{
+			name: "state store",
+			opts: jet.Options{StateStore: jet.NewMemoryStateStore(), AlwaysUseStateStore: true},
+		},
+		{
+			name: "compact in state store",
+			opts: jet.Options{StateStore: jet.NewMemoryStateStore(), AlwaysUseStateStore: true},
+			flow: &jet.FlowOptions{CompactMetadata: true},
+		},
+		{
+			name: "compact and sealed in state store",
+			opts: jet.Options{StateStore: jet.NewMemoryStateStore(), AlwaysUseStateStore: true, MetadataSecret: &jet.MetadataSecret{Keys: [][]byte{[]byte("0123456789abcdef0123456789abcdef")}, Encrypt: true}},
+			flow: &jet.FlowOptions{CompactMetadata: true},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			srv := jettest.NewServer()
+			defer srv.Close()
+
+			builder := jet.NewBuilder()
+			counter, err := builder.AddFlow(counterFlow("counter", test.flow))
+			if err != nil {
+				t.Fatal(err)
+			}
+			app := builder.AddSlash("/counter", func(ctx jet.Context, args slack.SlashCommand) (*jet.Message, error) {
+				return ctx.StartFlow(counter, nil)
+			}).Build(srv.Options(test.opts))
+
+			ctx := context.Background()
+			msg, err := srv.SlashCommand(ctx, app, "/counter", "")
+			if err != nil {
+				t.Fatal(err)
+			}
+			for i := 1; i <= 3; i++ {
+				_, err = srv.ClickButton(ctx, app, msg, "jet_counter_cb_1", "")
+				if err != nil {
+					t.Fatalf("click %d: %v", i, err)
+				}
+				current := srv.Message(msg.ChannelID, msg.Timestamp)
+				expected := fmt.Sprintf("count: %d", i)
+				if current.Text != expected {
+					t.Fatalf("expected %q, got %q", expected, current.Text)
+				}
+			}
+		})
+	}
+}
+
+// trackingStateStore records which states are still stored and which were deleted
+type trackingStateStore struct {
+	jet.StateStore
+	lock    sync.Mutex
+	keys    map[string]bool
+	deleted map[string]bool
+}
+
+func newTrackingStateStore() *trackingStateStore {
+	return &trackingStateStore{
+		StateStore: jet.NewMemoryStateStore(),
+		keys:       make(map[string]bool),
+		deleted:    make(map[string]bool),
+	}
+}
+
+func (me *trackingStateStore) Put(ctx context.Context, key string, value []byte) error {
+	me.lock.Lock()
+	me.keys[key] = true
+	me.lock.Unlock()
+	return me.StateStore.Put(ctx, key, value)
+}
+
+func (me *trackingStateStore) Delete(ctx context.Context, key string) error {
+	me.lock.Lock()
+	delete(me.keys, key)
+	me.deleted[key] = true
+	me.lock.Unlock()
+	return me.StateStore.Delete(ctx, key)
+}
+
+func (me *trackingStateStore) Len() int {
+	me.lock.Lock()
+	defer me.lock.Unlock()
+	return len(me.keys)
+}
+
+func (me *trackingStateStore) Deleted() int {
+	me.lock.Lock()
+	defer me.lock.Unlock()
+	return len(me.deleted)
+}
+
+func waitFor(t *testing.T, what string, check func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for !check() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestReplacedStateIsKeptDuringGracePeriod(t *testing.T) {
+	srv := jettest.NewServer()
+	defer srv.Close()
+
+	store := newTrackingStateStore()
+	builder := jet.NewBuilder()
+	counter, err := builder.AddFlow(counterFlow("counter", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	app := builder.AddSlash("/counter", func(ctx jet.Context, args slack.SlashCommand) (*jet.Message, error) {
+		return ctx.StartFlow(counter, nil)
+	}).Build(srv.Options(jet.Options{
+		StateStore:            store,
+		AlwaysUseStateStore:   true,
+		StateStoreGracePeriod: 100 * time.Millisecond,
+	}))
+
+	ctx := context.Background()
+	msg, err := srv.SlashCommand(ctx, app, "/counter", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	stale := *srv.Message(msg.ChannelID, msg.Timestamp)
+	_, err = srv.ClickButton(ctx, app, msg, "jet_counter_cb_1", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Slack can still send the previous metadata, e.g. for a click before the message is refreshed
+	_, err = app.HandleInteraction(ctx, slack.InteractionCallback{
+		Type:        slack.InteractionTypeBlockActions,
+		Team:        slack.Team{ID: srv.TeamID},
+		User:        slack.User{ID: srv.UserID, TeamID: srv.TeamID},
+		Channel:     slack.Channel{GroupConversation: slack.GroupConversation{Conversation: slack.Conversation{ID: stale.ChannelID}}},
+		Message:     slack.Message{Msg: stale.Msg},
+		TriggerID:   srv.NewTriggerID(srv.UserID),
+		ResponseURL: srv.NewResponseURL(stale.ChannelID, stale.Timestamp),
+		ActionCallback: slack.ActionCallbacks{
+			BlockActions: []*slack.BlockAction{{ActionID: "jet_counter_cb_1"}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("stale click: %v", err)
+	}
+
+	current := srv.Message(msg.ChannelID, msg.Timestamp)
+	if current.Text != "count: 1" {
+		t.Fatalf("expected the stale click to render from the previous state, got %q", current.Text)
+	}
+
+	// only the state of the initial render was replaced (twice)
+	waitFor(t, "the replaced state to be deleted", func() bool {
+		return store.Deleted() == 1
+	})
+	_, err = srv.ClickButton(ctx, app, msg, "jet_counter_cb_1", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestPostFlowReleasesInitialState(t *testing.T) {
+	srv := jettest.NewServer()
+	defer srv.Close()
+
+	store := newTrackingStateStore()
+	builder := jet.NewBuilder()
+	loader, err := builder.AddFlow(jet.NewFlow("loader", func(ctx jet.RenderContext, props jet.FlowProps) (*jet.RenderedFlow, error) {
+		loaded, setLoaded, err := jet.UseState(ctx, false)
+		if err != nil {
+			return nil, err
+		}
+		err = jet.UseEffectAtStart(ctx, func(ctx context.Context) error {
+			return setLoaded(true)
+		})
+		if err != nil {
+			return nil, err
+		}
+		return &jet.RenderedFlow{
+			Text: fmt.Sprintf("loaded: %v", loaded),
+		}, nil
+	}, &jet.FlowOptions{CanUpdateWithoutInteraction: true}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	app := builder.AddSlash("/load", func(ctx jet.Context, args slack.SlashCommand) (*jet.Message, error) {
+		return ctx.StartFlow(loader, nil)
+	}).Build(srv.Options(jet.Options{
+		StateStore:            store,
+		AlwaysUseStateStore:   true,
+		StateStoreGracePeriod: time.Millisecond,
+	}))
+
+	_, err = srv.SlashCommand(context.Background(), app, "/load", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, "the effect to update the message", func() bool {
+		msg := srv.LastMessage(srv.ChannelID)
+		return msg != nil && msg.Text == "loaded: true"
+	})
+	waitFor(t, "the initial state to be deleted", func() bool {
+		return store.Len() == 1
+	})
+}
+
+func TestMessageMetadataLimit(t *testing.T) {
+	big := jet.NewFlow("big", func(ctx jet.RenderContext, props jet.FlowProps) (*jet.RenderedFlow, error) {
+		return &jet.RenderedFlow{
+			Text: "big",
+		}, nil
+	}, nil)
+	props := jet.FlowProps{"text": strings.Repeat("x", 9000)}
+
+	tests := []struct {
+		name   string
+		limit  int
+		store  bool
+		stored int
+		posted bool
+	}{
+		{name: "stored by default", store: true, stored: 1, posted: true},
+		{name: "error without store", posted: false},
+		{name: "check disabled", limit: -1, store: true, posted: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			srv := jettest.NewServer()
+			defer srv.Close()
+
+			store := newTrackingStateStore()
+			opts := jet.Options{MessageMetadataLimit: test.limit}
+			if test.store {
+				opts.StateStore = store
+			}
+			builder := jet.NewBuilder()
+			flow, err := builder.AddFlow(big)
+			if err != nil {
+				t.Fatal(err)
+			}
+			app := builder.AddSlash("/big", func(ctx jet.Context, args slack.SlashCommand) (*jet.Message, error) {
+				return ctx.StartFlow(flow, props)
+			}).Build(srv.Options(opts))
+
+			msg, _ := srv.SlashCommand(context.Background(), app, "/big", "")
+			if posted := msg != nil && msg.Text == "big"; posted != test.posted {
+				t.Errorf("expected posted to be %v, got %+v", test.posted, msg)
+			}
+			if store.Len() != test.stored {
+				t.Errorf("expected %d stored states, got %d", test.stored, store.Len())
+			}
+		})
+	}
+}
+
+func TestModalStateIsReleased(t *testing.T) {
+	tests := []struct {
+		name     string
+		response func(ctx jet.Context, next *jet.FlowHandle) (*jet.ViewResponse, error)
+		close    bool
+		released bool
+	}{
+		{name: "closed", close: true, released: true},
+		{
+			name: "submitted",
+			response: func(ctx jet.Context, next *jet.FlowHandle) (*jet.ViewResponse, error) {
+				return nil, nil
+			},
+			released: true,
+		},
+		{
+			name: "cleared",
+			response: func(ctx jet.Context, next *jet.FlowHandle) (*jet.ViewResponse, error) {
+				return jet.ClearViews(), nil
+			},
+			released: true,
+		},
+		{
+			name: "updated",
+			response: func(ctx jet.Context, next *jet.FlowHandle) (*jet.ViewResponse, error) {
+				msg, err := ctx.StartFlow(next, nil)
+				return jet.UpdateView(msg), err
+			},
+			released: true,
+		},
+		{
+			name: "pushed",
+			response: func(ctx jet.Context, next *jet.FlowHandle) (*jet.ViewResponse, error) {
+				msg, err := ctx.StartFlow(next, nil)
+				return jet.PushView(msg), err
+			},
+		},
+		{
+			name: "invalid",
+			response: func(ctx jet.Context, next *jet.FlowHandle) (*jet.ViewResponse, error) {
+				return nil, jet.ViewErrors{"name": "Required"}
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			srv := jettest.NewServer()
+			defer srv.Close()
+
+			store := newTrackingStateStore()
+			builder := jet.NewBuilder()
+			form, err := builder.AddFlow(jet.NewFlow("form", func(ctx jet.RenderContext, props jet.FlowProps) (*jet.RenderedFlow, error) {
+				return &jet.RenderedFlow{
+					ForModal: &jet.ModalConfig{Title: plainText("Form"), Submit: plainText("Submit"), NotifyOnClose: true},
+				}, nil
+			}, nil))
+			if err != nil {
+				t.Fatal(err)
+			}
+			next, err := builder.AddFlow(modalFlow("next"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			builder = builder.AddSlash("/form", func(ctx jet.Context, args slack.SlashCommand) (*jet.Message, error) {
+				msg, err := ctx.StartFlow(form, nil)
+				if err != nil {
+					return nil, err
+				}
+				return nil, ctx.OpenModal(msg, "")
+			})
+			if test.response != nil {
+				builder = builder.HandleSubmittedView("form", func(ctx jet.Context, args slack.InteractionCallback) (*jet.ViewResponse, error) {
+					return test.response(ctx, next)
+				})
+			}
+			app := builder.Build(srv.Options(jet.Options{
+				StateStore:            store,
+				AlwaysUseStateStore:   true,
+				StateStoreGracePeriod: time.Millisecond,
+			}))
+
+			ctx := context.Background()
+			_, err = srv.SlashCommand(ctx, app, "/form", "")
+			if err != nil {
+				t.Fatal(err)
+			}
+			views := srv.Views()
+			if len(views) != 1 {
+				t.Fatalf("expected the form to be opened, got %d views", len(views))
+			}
+			if test.close {
+				err = srv.CloseView(ctx, app, &views[0])
+			} else {
+				_, err = srv.SubmitView(ctx, app, &views[0], nil)
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if test.released {
+				waitFor(t, "the state of the form to be deleted", func() bool {
+					return store.Deleted() == 1
+				})
+				return
+			}
+			time.Sleep(20 * time.Millisecond)
+			if store.Deleted() != 0 {
+				t.Errorf("expected the state of the form to be kept, %d deleted", store.Deleted())
+			}
+		})
+	}
+}
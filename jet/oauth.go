@@ -0,0 +1,222 @@
+package jet
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	oauthAuthorizeURL    = "https://slack.com/oauth/v2/authorize"
+	oauthStateCookie     = "jet_oauth_state"
+	defaultOAuthStateTTL = 10 * time.Minute
+)
+
+var ErrInvalidOAuthState = errors.New("invalid oauth state")
+
+type oauthState struct {
+	Nonce   string `json:"n"`
+	Expires int64  `json:"e"`
+	State   string `json:"s,omitempty"`
+}
+
+var oauthStateEncoding = base64.RawURLEncoding
+
+func oauthStateSecret(cfg *OAuthConfig) []byte {
+	if cfg == nil {
+		return nil
+	}
+	if len(cfg.StateSecret) > 0 {
+		return cfg.StateSecret
+	}
+	secret := make([]byte, 32)
+	_, _ = rand.Read(secret)
+	return secret
+}
+
+func (me *app) oauthStateTTL() time.Duration {
+	if me.opts.OAuthConfig.StateTTL > 0 {
+		return me.opts.OAuthConfig.StateTTL
+	}
+	return defaultOAuthStateTTL
+}
+
+func (me *app) signOAuthState(payload string) string {
+	mac := hmac.New(sha256.New, me.oauthStateSecret)
+	mac.Write([]byte(payload))
+	return oauthStateEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// newOAuthState returns the signed state sent to Slack and the nonce which must be kept in the browser
+func (me *app) newOAuthState(state string) (string, string, error) {
+	nonce := make([]byte, 16)
+	_, err := rand.Read(nonce)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	data, err := json.Marshal(oauthState{
+		Nonce:   oauthStateEncoding.EncodeToString(nonce),
+		Expires: time.Now().Add(me.oauthStateTTL()).Unix(),
+		State:   state,
+	})
+	if err != nil {
+		return "", "", fmt.Errorf("failed to marshal state: %w", err)
+	}
+	payload := oauthStateEncoding.EncodeToString(data)
+	return payload + "." + me.signOAuthState(payload), oauthStateEncoding.EncodeToString(nonce), nil
+}
+
+// verifyOAuthState checks the state coming back from Slack against the browser's cookie and returns the original state
+func (me *app) verifyOAuthState(signed string, r *http.Request) (string, error) {
+	payload, signature, found := strings.Cut(signed, ".")
+	if !found {
+		return "", fmt.Errorf("%w: malformed", ErrInvalidOAuthState)
+	}
+	if !hmac.Equal([]byte(signature), []byte(me.signOAuthState(payload))) {
+		return "", fmt.Errorf("%w: bad signature", ErrInvalidOAuthState)
+	}
+	data, err := oauthStateEncoding.DecodeString(payload)
+	if err != nil {
+		return "", fmt.Errorf("%w: malformed", ErrInvalidOAuthState)
+	}
+	var state oauthState
+	err = json.Unmarshal(data, &state)
+	if err != nil {
+		return "", fmt.Errorf("%w: malformed", ErrInvalidOAuthState)
+	}
+	if time.Now().Unix() > state.Expires {
+		return "", fmt.Errorf("%w: expired", ErrInvalidOAuthState)
+	}
+	cookie, err := r.Cookie(oauthStateCookie)
+	if err != nil {
+		return "", fmt.Errorf("%w: missing cookie, the install must be completed in the browser it was started from", ErrInvalidOAuthState)
+	}
+	if subtle.ConstantTimeCompare([]byte(cookie.Value), []byte(state.Nonce)) != 1 {
+		return "", fmt.Errorf("%w: cookie mismatch", ErrInvalidOAuthState)
+	}
+	return state.State, nil
+}
+
+func setOAuthStateCookie(w http.ResponseWriter, value string, maxAge int) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     oauthStateCookie,
+		Value:    value,
+		Path:     "/",
+		MaxAge:   maxAge,
+		Secure:   true,
+		HttpOnly: true,
+		// the browser must send it back when redirected from Slack
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+func oauthNotConfigured() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotImplemented)
+		w.Write([]byte("OAuth is not configured correctly, please provide `OAuthConfig` to `jet.NewBuilder().Build()`"))
+	})
+}
+
+func (me *app) StartOAuth(ctx context.Context, state string) http.Handler {
+	cfg := me.opts.OAuthConfig
+
+	if cfg == nil {
+		return oauthNotConfigured()
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		signed, nonce, err := me.newOAuthState(state)
+		if err != nil {
+			me.LogErrorf("failed to start oauth: %+v", err)
+			cfg.RenderErrorPage(err).ServeHTTP(w, r)
+			return
+		}
+
+		query := url.Values{
+			"client_id": {cfg.ClientID},
+			"scope":     {strings.Join(cfg.Scopes, ",")},
+			"state":     {signed},
+		}
+		if len(cfg.UserScopes) > 0 {
+			query.Set("user_scope", strings.Join(cfg.UserScopes, ","))
+		}
+		if cfg.RedirectURL != "" {
+			query.Set("redirect_uri", cfg.RedirectURL)
+		}
+
+		setOAuthStateCookie(w, nonce, int(me.oauthStateTTL().Seconds()))
+		http.Redirect(w, r, oauthAuthorizeURL+"?"+query.Encode(), http.StatusFound)
+	})
+}
+
+func (me *app) FinalizeOAuth(ctx context.Context, code, state string) http.Handler {
+	cfg := me.opts.OAuthConfig
+
+	if cfg == nil {
+		return oauthNotConfigured()
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !cfg.DisableStateVerification {
+			var err error
+			state, err = me.verifyOAuthState(state, r)
+			if err != nil {
+				me.LogErrorf("failed to handle oauth: %+v", err)
+				cfg.RenderErrorPage(err).ServeHTTP(w, r)
+				return
+			}
+			// the state can only be used once
+			setOAuthStateCookie(w, "", -1)
+		}
+
+		if code == "" {
+			err := fmt.Errorf("missing code in oauth request (slack issue)")
+			me.LogErrorf("failed to handle oauth: %+v", err)
+			cfg.RenderErrorPage(err).ServeHTTP(w, r)
+			return
+		}
+
+		resp, err := me.tokenExchange(ctx, code, cfg.ClientID, cfg.ClientSecret, cfg.RedirectURL)
+		if err != nil {
+			me.LogErrorf("failed to exchange code for token: %+v", err)
+			cfg.RenderErrorPage(err).ServeHTTP(w, r)
+			return
+		}
+
+		installation := newInstallation(resp, time.Now())
+		if me.opts.InstallationStore != nil {
+			err = me.opts.InstallationStore.Save(ctx, installation)
+			if err != nil {
+				me.LogErrorf("failed to save installation: %+v", err)
+				cfg.RenderErrorPage(err).ServeHTTP(w, r)
+				return
+			}
+		}
+
+		if cfg.OnSuccess != nil {
+			err = cfg.OnSuccess(OAuthSuccessData{
+				TeamID:       resp.Team.ID,
+				AccessToken:  resp.AccessToken,
+				State:        state,
+				Installation: installation,
+			})
+			if err != nil {
+				me.LogErrorf("failed to handle oauth: %+v", err)
+				cfg.RenderErrorPage(err).ServeHTTP(w, r)
+				return
+			}
+		}
+
+		cfg.RenderSuccessPage.ServeHTTP(w, r)
+	})
+}
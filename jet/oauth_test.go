@@ -0,0 +1,178 @@
+package jet
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+type oauthResult struct {
+	err     error
+	success *OAuthSuccessData
+}
+
+func newOAuthApp(t *testing.T, disableVerification bool) (*app, *oauthResult) {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"ok":           true,
+			"access_token": "xoxb-installed",
+			"team":         map[string]any{"id": "T1"},
+		})
+	}))
+	t.Cleanup(srv.Close)
+
+	result := &oauthResult{}
+	built := NewBuilder().Build(Options{
+		APIURL: srv.URL + "/api/",
+		OAuthConfig: &OAuthConfig{
+			ClientID:                 "client",
+			ClientSecret:             "secret",
+			StateSecret:              []byte("state-secret"),
+			DisableStateVerification: disableVerification,
+			OnSuccess: func(data OAuthSuccessData) error {
+				result.success = &data
+				return nil
+			},
+			RenderSuccessPage: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			}),
+			RenderErrorPage: func(err error) http.Handler {
+				result.err = err
+				return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					w.WriteHeader(http.StatusBadRequest)
+				})
+			},
+		},
+	})
+	return built.(*app), result
+}
+
+// startOAuth returns the signed state sent to Slack and the nonce cookie set in the browser
+func startOAuth(t *testing.T, app *app, state string) (string, *http.Cookie) {
+	t.Helper()
+	res := httptest.NewRecorder()
+	app.StartOAuth(context.Background(), state).ServeHTTP(res, httptest.NewRequest(http.MethodGet, "/install", nil))
+	if res.Code != http.StatusFound {
+		t.Fatalf("expected a redirect, got %d", res.Code)
+	}
+	location, err := url.Parse(res.Header().Get("Location"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	cookies := res.Result().Cookies()
+	if len(cookies) != 1 || cookies[0].Name != oauthStateCookie {
+		t.Fatalf("expected the nonce cookie, got %+v", cookies)
+	}
+	return location.Query().Get("state"), cookies[0]
+}
+
+func finalizeOAuth(app *app, state string, cookie *http.Cookie) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/oauth", nil)
+	if cookie != nil {
+		req.AddCookie(cookie)
+	}
+	res := httptest.NewRecorder()
+	app.FinalizeOAuth(context.Background(), "code", state).ServeHTTP(res, req)
+	return res
+}
+
+func TestOAuthState(t *testing.T) {
+	t.Run("valid", func(t *testing.T) {
+		app, result := newOAuthApp(t, false)
+		state, cookie := startOAuth(t, app, "origin")
+		res := finalizeOAuth(app, state, cookie)
+		if res.Code != http.StatusOK || result.err != nil {
+			t.Fatalf("expected the install to succeed, got %d (%v)", res.Code, result.err)
+		}
+		if result.success == nil || result.success.State != "origin" || result.success.AccessToken != "xoxb-installed" {
+			t.Errorf("expected the original state to be passed back, got %+v", result.success)
+		}
+		cleared := res.Result().Cookies()
+		if len(cleared) != 1 || cleared[0].Name != oauthStateCookie || cleared[0].MaxAge >= 0 {
+			t.Errorf("expected the nonce cookie to be cleared, got %+v", cleared)
+		}
+	})
+
+	tests := []struct {
+		name   string
+		tamper func(t *testing.T, app *app, state string, cookie *http.Cookie) (string, *http.Cookie)
+	}{
+		{
+			name: "expired",
+			tamper: func(t *testing.T, app *app, state string, cookie *http.Cookie) (string, *http.Cookie) {
+				data, err := json.Marshal(oauthState{Nonce: cookie.Value, Expires: time.Now().Add(-time.Minute).Unix(), State: "origin"})
+				if err != nil {
+					t.Fatal(err)
+				}
+				payload := oauthStateEncoding.EncodeToString(data)
+				return payload + "." + app.signOAuthState(payload), cookie
+			},
+		},
+		{
+			name: "tampered signature",
+			tamper: func(t *testing.T, app *app, state string, cookie *http.Cookie) (string, *http.Cookie) {
+				payload, _, _ := strings.Cut(state, ".")
+				return payload + "." + app.signOAuthState(payload+"x"), cookie
+			},
+		},
+		{
+			name: "tampered payload",
+			tamper: func(t *testing.T, app *app, state string, cookie *http.Cookie) (string, *http.Cookie) {
+				_, signature, _ := strings.Cut(state, ".")
+				data, err := json.Marshal(oauthState{Nonce: cookie.Value, Expires: time.Now().Add(time.Hour).Unix(), State: "elsewhere"})
+				if err != nil {
+					t.Fatal(err)
+				}
+				return oauthStateEncoding.EncodeToString(data) + "." + signature, cookie
+			},
+		},
+		{
+			name: "cookie mismatch",
+			tamper: func(t *testing.T, app *app, state string, cookie *http.Cookie) (string, *http.Cookie) {
+				_, other := startOAuth(t, app, "origin")
+				return state, other
+			},
+		},
+		{
+			name: "missing cookie",
+			tamper: func(t *testing.T, app *app, state string, cookie *http.Cookie) (string, *http.Cookie) {
+				return state, nil
+			},
+		},
+		{
+			name: "unsigned",
+			tamper: func(t *testing.T, app *app, state string, cookie *http.Cookie) (string, *http.Cookie) {
+				return "origin", cookie
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			app, result := newOAuthApp(t, false)
+			state, cookie := startOAuth(t, app, "origin")
+			state, cookie = test.tamper(t, app, state, cookie)
+			res := finalizeOAuth(app, state, cookie)
+			if res.Code != http.StatusBadRequest || !errors.Is(result.err, ErrInvalidOAuthState) {
+				t.Fatalf("expected the state to be rejected, got %d (%v)", res.Code, result.err)
+			}
+			if result.success != nil {
+				t.Errorf("expected the install to be aborted, got %+v", result.success)
+			}
+		})
+	}
+
+	t.Run("verification disabled", func(t *testing.T) {
+		app, result := newOAuthApp(t, true)
+		res := finalizeOAuth(app, "origin", nil)
+		if res.Code != http.StatusOK || result.success == nil || result.success.State != "origin" {
+			t.Fatalf("expected the state to be passed as is, got %d (%v, %+v)", res.Code, result.err, result.success)
+		}
+	})
+}
@@ -2,9 +2,14 @@ package jet
 
 import (
 	"net/http"
+	"time"
+
+	"github.com/slack-go/slack"
 )
 
-type AccessTokenRetriever func(teamID string) (string, error)
+type AccessTokenRetriever func(id string) (string, error)
+
+type ClientFactory func(teamID, token string) *slack.Client
 
 type Credentials struct {
 	// The signing secret used to verify incoming webhooks.
@@ -15,13 +20,20 @@ type Credentials struct {
 	// This will be called every time a request is made to a particular team.
 	// It is your job to cache the token for future use if you want to.
 	// If you return an error, the request will be aborted.
+	// If nil, the bot token of the installation from `Options.InstallationStore` is used instead.
 	GetAccessToken AccessTokenRetriever
+
+	// Same as GetAccessToken but for organization-wide installs on Enterprise Grid, it receives the enterprise ID instead.
+	// It is used when GetAccessToken is nil or returns an error and the request comes from an enterprise.
+	GetEnterpriseAccessToken AccessTokenRetriever
 }
 
 type OAuthSuccessData struct {
 	State       string
 	TeamID      string
 	AccessToken string
+	// everything returned by Slack, already saved if an InstallationStore is configured
+	Installation Installation
 }
 
 type OAuthSuccessHandler func(data OAuthSuccessData) error
@@ -34,7 +46,24 @@ type OAuthConfig struct {
 	// same as above
 	ClientSecret string
 
-	// called if the OAuth flow is successful
+	// the scopes requested when installing the app (through StartOAuth)
+	Scopes []string
+	// the user scopes requested when installing the app (through StartOAuth)
+	UserScopes []string
+	// must match one of the redirect URLs of the app, optional if there is only one
+	RedirectURL string
+
+	// used to sign the OAuth state, a random one is generated if empty (which means installs in progress will fail after a restart)
+	StateSecret []byte
+	// how long the user has to complete the install, defaults to 10 minutes
+	StateTTL time.Duration
+	// skip the state and cookie verification in FinalizeOAuth, e.g. if the installs are not started with StartOAuth
+	DisableStateVerification bool
+
+	// with token rotation, how long before expiring the tokens of the InstallationStore are refreshed, defaults to 5 minutes
+	TokenRefreshMargin time.Duration
+
+	// called if the OAuth flow is successful (optional if an InstallationStore is configured)
 	OnSuccess OAuthSuccessHandler
 
 	// render the page that will be displayed to the user after the OAuth flow is successful
@@ -46,8 +75,38 @@ type OAuthConfig struct {
 type ErrorFormatter = func(error) Message
 
 type Options struct {
-	Credentials    Credentials
-	OAuthConfig    *OAuthConfig
-	Logger         Logger
-	ErrorFormatter ErrorFormatter
+	Credentials Credentials
+	OAuthConfig *OAuthConfig
+	// Keeps the installations created through OAuth, also used to get the access tokens if `Credentials.GetAccessToken` is nil.
+	InstallationStore InstallationStore
+	Logger            Logger
+	ErrorFormatter    ErrorFormatter
+
+	// Used to keep the flow state outside of Slack when it doesn't fit in the metadata.
+	// Without it, rendering a flow which is too large will fail with ErrMetadataTooLarge.
+	StateStore StateStore
+	// Always keep the flow state in the StateStore, even when it would fit in the metadata.
+	AlwaysUseStateStore bool
+	// How long a replaced state is kept in the StateStore, so that interactions and async updates still referring to it keep working, defaults to 15 minutes.
+	// States waiting to be deleted when the process exits stay in the StateStore.
+	StateStoreGracePeriod time.Duration
+	// Maximum size (in characters) of the metadata attached to messages, defaults to Slack's limit (8000), a negative value disables the check.
+	// Views are always limited to 3000 characters by Slack.
+	MessageMetadataLimit int
+	// Base URL of the Slack Web API (e.g. to use a fake server in tests), it must end with a slash.
+	APIURL string
+	// HTTP client used to call the Slack Web API, post to `response_url`s and exchange OAuth codes.
+	// Use it to configure proxies, timeouts or custom transports.
+	HTTPClient *http.Client
+	// Extra options for the Slack clients (e.g. `slack.OptionDebug`), applied after APIURL and HTTPClient.
+	SlackClientOptions []slack.Option
+	// Creates the Slack client for a team, replaces APIURL, HTTPClient and SlackClientOptions.
+	// Clients are cached per token, so this is only called once for each token.
+	ClientFactory ClientFactory
+	// How calls to Slack are retried when rate limited or failing temporarily, DefaultRetryPolicy is used if nil (or for the fields left empty).
+	RetryPolicy *RetryPolicy
+
+	// Sign (or encrypt) the state and props that jet stores in the metadata, so they cannot be tampered with.
+	// Once set, unsigned metadata is rejected.
+	MetadataSecret *MetadataSecret
 }
@@ -11,6 +11,14 @@ import (
 type SourceInfo struct {
 	TeamID string
 	UserID string
+	// only for Enterprise Grid
+	EnterpriseID string
+	// when it happened in a channel
+	ChannelID string
+	// only for message shortcuts
+	MessageTS string
+	ThreadTS  string
+	TriggerID string
 }
 
 type RenderContext interface {
@@ -18,7 +26,9 @@ type RenderContext interface {
 	Source() SourceInfo
 	addState(initial func() (json.RawMessage, error)) (int, json.RawMessage, func(newValue json.RawMessage), error)
 	addCallback(callback Callback) (string, error)
+	addOptionsLoader(loader OptionsLoader) (string, error)
 	addEffect(effect Effect) error
+	addCloseHandler(handler CloseHandler) error
 	getAsyncData() *asyncStateData
 }
 
@@ -26,9 +36,12 @@ type hookData struct {
 	kind string
 	// for state
 	data json.RawMessage
-	// for callback
-	callback   Callback
-	callbackID string
+	// for callback/options
+	callback      Callback
+	optionsLoader OptionsLoader
+	callbackID    string
+	// for close
+	closeHandler CloseHandler
 }
 
 type renderContext struct {
@@ -97,9 +110,22 @@ func (me *renderContext) serializeHooks() []slackMetadataHook {
 const (
 	hookState       = "state"
 	hookCallback    = "callback"
+	hookOptions     = "options"
 	hookEffectStart = "effect-start"
+	hookClose       = "close"
 )
 
+func defaultCallbackID(flow, kind string, idx int) string {
+	switch kind {
+	case hookCallback:
+		return fmt.Sprintf("jet_%s_cb_%x", flow, idx)
+	case hookOptions:
+		return fmt.Sprintf("jet_%s_opt_%x", flow, idx)
+	default:
+		return ""
+	}
+}
+
 func (me *renderContext) addState(initial func() (json.RawMessage, error)) (int, json.RawMessage, func(newValue json.RawMessage), error) {
 	id, prev, err := me.fetchHook(hookState)
 	if err != nil {
@@ -136,7 +162,7 @@ func (me *renderContext) addCallback(callback Callback) (string, error) {
 	}
 	prev.callback = callback
 	if me.isInitial {
-		prev.callbackID = fmt.Sprintf("jet_%s_cb_%x", me.name, id)
+		prev.callbackID = defaultCallbackID(me.name, hookCallback, id)
 	}
 	me.addedHooks = append(me.addedHooks, prev)
 	return prev.callbackID, nil
@@ -152,6 +178,29 @@ func (me *renderContext) triggerCallback(callbackID string, action slack.BlockAc
 	return fmt.Errorf("unknown callback: %s", callbackID)
 }
 
+func (me *renderContext) addOptionsLoader(loader OptionsLoader) (string, error) {
+	id, prev, err := me.fetchHook(hookOptions)
+	if err != nil {
+		return "", err
+	}
+	prev.optionsLoader = loader
+	if me.isInitial {
+		prev.callbackID = defaultCallbackID(me.name, hookOptions, id)
+	}
+	me.addedHooks = append(me.addedHooks, prev)
+	return prev.callbackID, nil
+}
+
+func (me *renderContext) loadOptions(actionID, query string) ([]*slack.OptionBlockObject, error) {
+	for _, hook := range me.expectedHooks {
+		if hook.kind != hookOptions || hook.callbackID != actionID {
+			continue
+		}
+		return hook.optionsLoader(me, query)
+	}
+	return nil, fmt.Errorf("unknown options loader: %s", actionID)
+}
+
 func (me *renderContext) addEffect(effect Effect) error {
 	_, prev, err := me.fetchHook(hookEffectStart)
 	if err != nil {
@@ -162,6 +211,29 @@ func (me *renderContext) addEffect(effect Effect) error {
 	return nil
 }
 
+func (me *renderContext) addCloseHandler(handler CloseHandler) error {
+	_, prev, err := me.fetchHook(hookClose)
+	if err != nil {
+		return err
+	}
+	prev.closeHandler = handler
+	me.addedHooks = append(me.addedHooks, prev)
+	return nil
+}
+
+func (me *renderContext) triggerClose(view slack.View) error {
+	for _, hook := range me.expectedHooks {
+		if hook.kind != hookClose {
+			continue
+		}
+		err := hook.closeHandler(me, view)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (me *renderContext) fetchHook(kind string) (int, *hookData, error) {
 	currentIdx := me.hookIdx
 	me.hookIdx += 1
@@ -0,0 +1,234 @@
+package jet
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"sync"
+	"time"
+
+	"github.com/slack-go/slack"
+)
+
+var ErrRateLimited = errors.New("rate limited")
+
+type RetryPolicy struct {
+	// Maximum number of attempts for a single call (including the first one), 1 disables retries.
+	MaxAttempts int
+	// Delay before retrying after a transient error, it is doubled after every attempt (with jitter) up to MaxBackoff.
+	// Rate limits use the delay given by Slack instead.
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+	// Calls allowed per minute for each team and method (e.g. "chat.update"), calls over the budget wait instead of being rate limited by Slack.
+	// It overrides DefaultMethodBudgets for the given methods, a negative budget removes the limit.
+	MethodBudgets map[string]int
+}
+
+// Zero values are replaced by the ones of DefaultRetryPolicy.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 3,
+	MinBackoff:  500 * time.Millisecond,
+	MaxBackoff:  10 * time.Second,
+}
+
+// DefaultMethodBudgets follows the rate limit tiers documented by Slack for the methods used by jet.
+var DefaultMethodBudgets = map[string]int{
+	"chat.postMessage":      60,
+	"chat.update":           50,
+	"conversations.history": 50,
+	"dialog.open":           100,
+	"views.open":            100,
+	"views.publish":         100,
+	"views.push":            100,
+	"views.update":          100,
+}
+
+// errors returned by Slack in the body which are worth retrying
+var transientSlackErrors = map[string]bool{
+	"internal_error":      true,
+	"fatal_error":         true,
+	"service_unavailable": true,
+	"request_timeout":     true,
+}
+
+// rateLimits tracks, for each team and method, the calls left in the budget and until when Slack asked us to stop calling it
+type rateLimits struct {
+	lock    sync.Mutex
+	blocked map[string]time.Time
+	budgets map[string]*budget
+}
+
+// budget is refilled continuously, up to a minute worth of calls
+type budget struct {
+	calls float64
+	last  time.Time
+}
+
+// reserve takes a call from the budget and returns how long to wait before making it
+func (me *rateLimits) reserve(key string, perMinute int, now time.Time) time.Duration {
+	me.lock.Lock()
+	defer me.lock.Unlock()
+	if me.budgets == nil {
+		me.budgets = make(map[string]*budget)
+	}
+	current, found := me.budgets[key]
+	if !found {
+		current = &budget{calls: float64(perMinute), last: now}
+		me.budgets[key] = current
+	}
+	perNanosecond := float64(perMinute) / float64(time.Minute)
+	current.calls = min(float64(perMinute), current.calls+float64(now.Sub(current.last))*perNanosecond)
+	current.last = now
+	current.calls--
+	if current.calls >= 0 {
+		return 0
+	}
+	return time.Duration(math.Ceil(-current.calls / perNanosecond))
+}
+
+// cancel gives back a reserved call which was not made
+func (me *rateLimits) cancel(key string) {
+	me.lock.Lock()
+	defer me.lock.Unlock()
+	if current, found := me.budgets[key]; found {
+		current.calls++
+	}
+}
+
+func (me *rateLimits) block(key string, until time.Time) {
+	me.lock.Lock()
+	defer me.lock.Unlock()
+	if me.blocked == nil {
+		me.blocked = make(map[string]time.Time)
+	}
+	if until.After(me.blocked[key]) {
+		me.blocked[key] = until
+	}
+}
+
+func (me *rateLimits) blockedFor(key string) time.Duration {
+	me.lock.Lock()
+	defer me.lock.Unlock()
+	until, found := me.blocked[key]
+	if !found {
+		return 0
+	}
+	wait := time.Until(until)
+	if wait <= 0 {
+		delete(me.blocked, key)
+		return 0
+	}
+	return wait
+}
+
+func (me *app) retryPolicy() RetryPolicy {
+	policy := DefaultRetryPolicy
+	custom := me.opts.RetryPolicy
+	if custom == nil {
+		return policy
+	}
+	if custom.MaxAttempts > 0 {
+		policy.MaxAttempts = custom.MaxAttempts
+	}
+	if custom.MinBackoff > 0 {
+		policy.MinBackoff = custom.MinBackoff
+	}
+	if custom.MaxBackoff > 0 {
+		policy.MaxBackoff = custom.MaxBackoff
+	}
+	policy.MethodBudgets = custom.MethodBudgets
+	return policy
+}
+
+// methodBudget returns the calls allowed per minute for the method, 0 if it isn't limited
+func (me RetryPolicy) methodBudget(method string) int {
+	perMinute, found := me.MethodBudgets[method]
+	if !found || perMinute == 0 {
+		perMinute = DefaultMethodBudgets[method]
+	}
+	return max(perMinute, 0)
+}
+
+// withRetry calls Slack, waiting for rate limits to expire and retrying transient errors.
+// Calls which are not idempotent (e.g. posting a message) are only retried when Slack rate limited them, as they were not processed.
+func (me *app) withRetry(ctx context.Context, teamID, method string, idempotent bool, call func() error) error {
+	policy := me.retryPolicy()
+	key := teamID + "/" + method
+
+	var err error
+	for attempt := 1; ; attempt++ {
+		wait := me.limits.blockedFor(key)
+		perMinute := policy.methodBudget(method)
+		if perMinute > 0 {
+			wait = max(wait, me.limits.reserve(key, perMinute, time.Now()))
+		}
+		if wait > 0 {
+			me.LogDebugf("waiting %v for rate limit on %s", wait, key)
+			if !sleepContext(ctx, wait) {
+				if perMinute > 0 {
+					me.limits.cancel(key)
+				}
+				return fmt.Errorf("%w: %s for team %q", ErrRateLimited, method, teamID)
+			}
+		}
+
+		err = call()
+		if err == nil {
+			return nil
+		}
+
+		var delay time.Duration
+		var rateLimited *slack.RateLimitedError
+		var statusErr slack.StatusCodeError
+		var slackErr slack.SlackErrorResponse
+		switch {
+		case errors.As(err, &rateLimited):
+			delay = rateLimited.RetryAfter
+			me.limits.block(key, time.Now().Add(delay))
+		case idempotent && errors.As(err, &statusErr) && statusErr.Code >= 500:
+			delay = backoff(policy, attempt)
+		case idempotent && errors.As(err, &slackErr) && transientSlackErrors[slackErr.Err]:
+			delay = backoff(policy, attempt)
+		default:
+			return err
+		}
+
+		if attempt >= policy.MaxAttempts {
+			return err
+		}
+		me.LogDebugf("retrying %s in %v (attempt %d): %v", method, delay, attempt+1, err)
+		if !sleepContext(ctx, delay) {
+			return err
+		}
+	}
+}
+
+func backoff(policy RetryPolicy, attempt int) time.Duration {
+	delay := policy.MinBackoff << (attempt - 1)
+	if delay > policy.MaxBackoff || delay <= 0 {
+		delay = policy.MaxBackoff
+	}
+	// "equal jitter", keeps at least half the delay
+	half := delay / 2
+	if half <= 0 {
+		return delay
+	}
+	return half + rand.N(half)
+}
+
+// sleepContext waits for the given duration, it returns false without waiting if the context would expire before then
+func sleepContext(ctx context.Context, wait time.Duration) bool {
+	if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < wait {
+		return false
+	}
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
@@ -0,0 +1,170 @@
+package jet
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/slack-go/slack"
+)
+
+func newRetryApp(policy *RetryPolicy) *app {
+	return NewBuilder().Build(Options{RetryPolicy: policy}).(*app)
+}
+
+func TestBackoff(t *testing.T) {
+	policy := RetryPolicy{MinBackoff: 100 * time.Millisecond, MaxBackoff: time.Second}
+	tests := []struct {
+		attempt int
+		max     time.Duration
+	}{
+		{attempt: 1, max: 100 * time.Millisecond},
+		{attempt: 2, max: 200 * time.Millisecond},
+		{attempt: 3, max: 400 * time.Millisecond},
+		{attempt: 5, max: time.Second},
+		{attempt: 100, max: time.Second},
+	}
+	for _, test := range tests {
+		for range 20 {
+			delay := backoff(policy, test.attempt)
+			if delay < test.max/2 || delay > test.max {
+				t.Fatalf("attempt %d: expected a delay between %v and %v, got %v", test.attempt, test.max/2, test.max, delay)
+			}
+		}
+	}
+}
+
+func TestRetryPolicyDefaults(t *testing.T) {
+	policy := newRetryApp(&RetryPolicy{MinBackoff: time.Millisecond}).retryPolicy()
+	if policy.MaxAttempts != DefaultRetryPolicy.MaxAttempts || policy.MaxBackoff != DefaultRetryPolicy.MaxBackoff || policy.MinBackoff != time.Millisecond {
+		t.Errorf("expected the empty fields to use the defaults, got %+v", policy)
+	}
+}
+
+func TestWithRetry(t *testing.T) {
+	transient := slack.SlackErrorResponse{Err: "internal_error"}
+	serverError := slack.StatusCodeError{Code: 503, Status: "Service Unavailable"}
+	tests := []struct {
+		name       string
+		idempotent bool
+		errors     []error
+		calls      int
+		failed     bool
+	}{
+		{name: "success", idempotent: true, calls: 1},
+		{name: "transient error", idempotent: true, errors: []error{transient, serverError}, calls: 3},
+		{name: "too many transient errors", idempotent: true, errors: []error{transient, transient, transient, transient}, calls: 3, failed: true},
+		{name: "not idempotent", errors: []error{serverError}, calls: 1, failed: true},
+		{name: "rate limited but not idempotent", errors: []error{&slack.RateLimitedError{RetryAfter: time.Millisecond}}, calls: 2},
+		{name: "permanent error", idempotent: true, errors: []error{slack.SlackErrorResponse{Err: "channel_not_found"}}, calls: 1, failed: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// MaxAttempts is left empty on purpose, it must use the default
+			app := newRetryApp(&RetryPolicy{MinBackoff: time.Millisecond, MaxBackoff: 2 * time.Millisecond})
+			calls := 0
+			err := app.withRetry(context.Background(), "T1", "test.method", test.idempotent, func() error {
+				calls++
+				if calls <= len(test.errors) {
+					return test.errors[calls-1]
+				}
+				return nil
+			})
+			if (err != nil) != test.failed {
+				t.Errorf("expected failed to be %v, got %v", test.failed, err)
+			}
+			if calls != test.calls {
+				t.Errorf("expected %d calls, got %d", test.calls, calls)
+			}
+		})
+	}
+}
+
+func TestWithRetryHonoursRetryAfter(t *testing.T) {
+	app := newRetryApp(nil)
+	ctx := context.Background()
+	retryAfter := 50 * time.Millisecond
+
+	start := time.Now()
+	calls := 0
+	err := app.withRetry(ctx, "T1", "test.method", false, func() error {
+		calls++
+		if calls == 1 {
+			return &slack.RateLimitedError{RetryAfter: retryAfter}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed < retryAfter {
+		t.Errorf("expected to wait for %v, only waited %v", retryAfter, elapsed)
+	}
+
+	// the other calls of the team and method wait as well, but not the others
+	deadline, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+	err = app.withRetry(deadline, "T1", "test.method", false, func() error {
+		return &slack.RateLimitedError{RetryAfter: time.Minute}
+	})
+	var rateLimited *slack.RateLimitedError
+	if !errors.As(err, &rateLimited) {
+		t.Errorf("expected the rate limit to be returned when the deadline is too short, got %v", err)
+	}
+	err = app.withRetry(deadline, "T1", "test.method", true, func() error {
+		t.Error("expected the call to wait for the rate limit")
+		return nil
+	})
+	if !errors.Is(err, ErrRateLimited) {
+		t.Errorf("expected ErrRateLimited when the deadline is too short, got %v", err)
+	}
+	for _, other := range [][2]string{{"T2", "test.method"}, {"T1", "other.method"}} {
+		err = app.withRetry(deadline, other[0], other[1], true, func() error {
+			return nil
+		})
+		if err != nil {
+			t.Errorf("expected %s on %s to be allowed, got %v", other[1], other[0], err)
+		}
+	}
+}
+
+func TestMethodBudgets(t *testing.T) {
+	var limits rateLimits
+	now := time.Now()
+	for i := range 60 {
+		wait := limits.reserve("T1/chat.update", 60, now)
+		if wait != 0 {
+			t.Fatalf("expected call %d to be within the budget, got a wait of %v", i, wait)
+		}
+	}
+	if wait := limits.reserve("T1/chat.update", 60, now); wait != time.Second {
+		t.Errorf("expected to wait for the budget to refill, got %v", wait)
+	}
+	limits.cancel("T1/chat.update")
+	if wait := limits.reserve("T2/chat.update", 60, now); wait != 0 {
+		t.Errorf("expected each team to have its own budget, got a wait of %v", wait)
+	}
+	if wait := limits.reserve("T1/chat.update", 60, now.Add(2*time.Second)); wait != 0 {
+		t.Errorf("expected the budget to be refilled, got a wait of %v", wait)
+	}
+
+	app := newRetryApp(&RetryPolicy{MethodBudgets: map[string]int{"test.method": 1, "chat.update": -1}})
+	policy := app.retryPolicy()
+	if policy.methodBudget("chat.update") != 0 || policy.methodBudget("views.open") != DefaultMethodBudgets["views.open"] {
+		t.Errorf("expected the budgets to override the defaults, got %+v", policy.MethodBudgets)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	call := func() error {
+		return nil
+	}
+	err := app.withRetry(ctx, "T1", "test.method", true, call)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = app.withRetry(ctx, "T1", "test.method", true, call)
+	if !errors.Is(err, ErrRateLimited) {
+		t.Errorf("expected the second call to exceed the budget, got %v", err)
+	}
+}
@@ -0,0 +1,54 @@
+package jet_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/LouisBrunner/jet/jet"
+	"github.com/LouisBrunner/jet/jet/jettest"
+	"github.com/slack-go/slack"
+)
+
+type quoteProps struct {
+	Text string `json:"text"`
+}
+
+func TestMessageShortcutStartsFlowInThread(t *testing.T) {
+	srv := jettest.NewServer()
+	defer srv.Close()
+
+	builder := jet.NewBuilder()
+	quote, err := builder.AddFlow(jet.NewFlow("quote", func(ctx jet.RenderContext, props jet.FlowProps) (*jet.RenderedFlow, error) {
+		var data quoteProps
+		err := jet.UnmarshalProps(props, &data)
+		if err != nil {
+			return nil, err
+		}
+		return &jet.RenderedFlow{Text: "> " + data.Text}, nil
+	}, nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	app := builder.AddMessageShortcut("quote", func(ctx jet.Context, args slack.InteractionCallback) error {
+		return jet.StartFlowInThread(ctx, quote, &quoteProps{Text: args.Message.Text})
+	}).Build(srv.Options(jet.Options{}))
+
+	ctx := context.Background()
+	original := srv.AddMessage(srv.ChannelID, slack.Msg{Text: "something worth quoting"})
+	err = srv.MessageShortcut(ctx, app, &original, "quote")
+	if err != nil {
+		t.Fatal(err)
+	}
+	reply := srv.LastMessage(srv.ChannelID)
+	if reply == nil || reply.Text != "> something worth quoting" {
+		t.Fatalf("expected the flow to quote the source message, got %+v", reply)
+	}
+	if reply.ThreadTimestamp != original.Timestamp {
+		t.Errorf("expected the reply in the thread of %q, got %q", original.Timestamp, reply.ThreadTimestamp)
+	}
+
+	err = srv.MessageShortcut(ctx, app, &original, "unknown")
+	if err == nil {
+		t.Error("expected unknown shortcuts to fail")
+	}
+}
@@ -6,19 +6,120 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/slack-go/slack"
 )
 
-func (me *app) makeClientFor(teamID string) (*slack.Client, error) {
-	if me.opts.Credentials.GetAccessToken == nil {
-		return nil, errors.New("missing GetAccessToken function")
-	}
-	token, err := me.opts.Credentials.GetAccessToken(teamID)
+func (me *app) makeClientFor(ctx context.Context, teamID, enterpriseID string) (*slack.Client, error) {
+	token, err := me.accessToken(ctx, teamID, enterpriseID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get access token for %q: %w", teamID, err)
 	}
-	return slack.New(token), nil
+	return me.clients.get(token, func() *slack.Client {
+		return me.newClient(teamID, token)
+	}), nil
+}
+
+// accessToken uses the Credentials if provided, otherwise the bot token of the installation (which is refreshed when needed).
+// On Enterprise Grid, the token of the workspace is preferred and the one of the organization is used as a fallback (for org-wide installs).
+func (me *app) accessToken(ctx context.Context, teamID, enterpriseID string) (string, error) {
+	creds := me.opts.Credentials
+	if creds.GetAccessToken != nil || creds.GetEnterpriseAccessToken != nil {
+		var token string
+		err := errors.New("missing GetAccessToken function")
+		if creds.GetAccessToken != nil && teamID != "" {
+			token, err = creds.GetAccessToken(teamID)
+		}
+		if err != nil && creds.GetEnterpriseAccessToken != nil && enterpriseID != "" {
+			token, err = creds.GetEnterpriseAccessToken(enterpriseID)
+		}
+		return token, err
+	}
+
+	if me.opts.InstallationStore == nil {
+		return "", errors.New("missing GetAccessToken function or InstallationStore")
+	}
+	installation, err := me.lookupInstallation(ctx, teamID, enterpriseID)
+	if err != nil {
+		return "", err
+	}
+	return me.botToken(ctx, installation)
+}
+
+// lookupInstallation prefers the installation of the workspace and falls back to the one of the organization
+func (me *app) lookupInstallation(ctx context.Context, teamID, enterpriseID string) (*Installation, error) {
+	var installation *Installation
+	err := ErrInstallationNotFound
+	if teamID != "" {
+		installation, err = me.opts.InstallationStore.FindByTeam(ctx, teamID)
+	}
+	if errors.Is(err, ErrInstallationNotFound) && enterpriseID != "" {
+		installation, err = me.opts.InstallationStore.FindByEnterprise(ctx, enterpriseID)
+	}
+	return installation, err
+}
+
+// clientFor prefers the token given with the options, it is not cached as it is usually short-lived
+func (me *app) clientFor(ctx context.Context, in messageOptions) (*slack.Client, error) {
+	if in.Token != "" {
+		return me.newClient(in.TeamID, in.Token), nil
+	}
+	return me.makeClientFor(ctx, in.TeamID, in.EnterpriseID)
+}
+
+func (me *app) newClient(teamID, token string) *slack.Client {
+	if me.opts.ClientFactory != nil {
+		return me.opts.ClientFactory(teamID, token)
+	}
+
+	options := []slack.Option{}
+	if me.opts.APIURL != "" {
+		options = append(options, slack.OptionAPIURL(me.opts.APIURL))
+	}
+	if me.opts.HTTPClient != nil {
+		options = append(options, slack.OptionHTTPClient(me.opts.HTTPClient))
+	}
+	options = append(options, me.opts.SlackClientOptions...)
+	return slack.New(token, options...)
+}
+
+func (me *app) httpClient() *http.Client {
+	if me.opts.HTTPClient != nil {
+		return me.opts.HTTPClient
+	}
+	return http.DefaultClient
+}
+
+// clientCache keeps one client per token, so they can reuse their connections
+type clientCache struct {
+	lock    sync.Mutex
+	clients map[string]*slack.Client
+}
+
+func (me *clientCache) get(token string, create func() *slack.Client) *slack.Client {
+	me.lock.Lock()
+	defer me.lock.Unlock()
+	client, found := me.clients[token]
+	if found {
+		return client
+	}
+	if me.clients == nil {
+		me.clients = make(map[string]*slack.Client)
+	}
+	client = create()
+	me.clients[token] = client
+	return client
+}
+
+func (me *clientCache) forget(token string) {
+	me.lock.Lock()
+	defer me.lock.Unlock()
+	delete(me.clients, token)
 }
 
 func prepareMessage(msg *slack.Msg, in messageOptions) []slack.MsgOption {
@@ -43,18 +144,30 @@ func prepareMessage(msg *slack.Msg, in messageOptions) []slack.MsgOption {
 	return options
 }
 
-func (me *app) getMessage(ctx context.Context, teamID, channelID, messageTS string) (*slack.Msg, error) {
-	client, err := me.makeClientFor(teamID)
+// messageMethod is used to track rate limits, messages sent through a response URL don't use the Web API
+func messageMethod(method string, in messageOptions) string {
+	if in.ResponseURL != "" {
+		return "response_url"
+	}
+	return method
+}
+
+func (me *app) getMessage(ctx context.Context, teamID, enterpriseID, channelID, messageTS string) (*slack.Msg, error) {
+	client, err := me.makeClientFor(ctx, teamID, enterpriseID)
 	if err != nil {
 		return nil, err
 	}
 
-	res, err := client.GetConversationHistoryContext(ctx, &slack.GetConversationHistoryParameters{
-		ChannelID:          channelID,
-		Inclusive:          true,
-		Latest:             messageTS,
-		Limit:              1,
-		IncludeAllMetadata: true,
+	var res *slack.GetConversationHistoryResponse
+	err = me.withRetry(ctx, teamID, "conversations.history", true, func() error {
+		res, err = client.GetConversationHistoryContext(ctx, &slack.GetConversationHistoryParameters{
+			ChannelID:          channelID,
+			Inclusive:          true,
+			Latest:             messageTS,
+			Limit:              1,
+			IncludeAllMetadata: true,
+		})
+		return err
 	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to get message: %w", err)
@@ -65,65 +178,99 @@ func (me *app) getMessage(ctx context.Context, teamID, channelID, messageTS stri
 	return &res.Messages[0].Msg, nil
 }
 
-func (me *app) createMessage(ctx context.Context, msg *slack.Msg, in messageOptions) (string, error) {
-	client, err := me.makeClientFor(in.TeamID)
+// encodeMessage returns a copy of the message with its metadata ready to be sent to Slack
+func (me *app) encodeMessage(ctx context.Context, msg *slack.Msg) (*slack.Msg, error) {
+	meta, err := me.encodeMetadata(ctx, msg.Metadata, me.messageMetadataLimit())
 	if err != nil {
-		return "", err
+		return nil, err
+	}
+	encoded := *msg
+	encoded.Metadata = meta
+	return &encoded, nil
+}
+
+// createMessage returns the timestamp of the new message and the metadata as it was sent to Slack
+func (me *app) createMessage(ctx context.Context, msg *slack.Msg, in messageOptions) (string, *slack.SlackMetadata, error) {
+	client, err := me.clientFor(ctx, in)
+	if err != nil {
+		return "", nil, err
+	}
+
+	msg, err = me.encodeMessage(ctx, msg)
+	if err != nil {
+		return "", nil, err
 	}
 
 	me.LogDebugf("creating message: %+v", msg)
-	_, ts, err := client.PostMessageContext(ctx, in.ChannelID,
-		prepareMessage(msg, in)...,
-	)
-	return ts, err
+	var ts string
+	err = me.withRetry(ctx, in.TeamID, messageMethod("chat.postMessage", in), false, func() error {
+		options := prepareMessage(msg, in)
+		if in.ThreadTS != "" {
+			options = append(options, slack.MsgOptionTS(in.ThreadTS))
+		}
+		_, ts, err = client.PostMessageContext(ctx, in.ChannelID, options...)
+		return err
+	})
+	return ts, &msg.Metadata, err
 }
 
 func (me *app) updateMessage(ctx context.Context, msg *slack.Msg, in messageOptions) error {
-	client, err := me.makeClientFor(in.TeamID)
+	client, err := me.clientFor(ctx, in)
+	if err != nil {
+		return err
+	}
+
+	msg, err = me.encodeMessage(ctx, msg)
 	if err != nil {
 		return err
 	}
 
 	me.LogDebugf("updating message: %+v", msg)
-	_, _, _, err = client.UpdateMessageContext(ctx, in.ChannelID, in.MessageTS,
-		prepareMessage(msg, in)...,
-	)
-	return err
+	return me.withRetry(ctx, in.TeamID, messageMethod("chat.update", in), true, func() error {
+		_, _, _, err := client.UpdateMessageContext(ctx, in.ChannelID, in.MessageTS,
+			prepareMessage(msg, in)...,
+		)
+		return err
+	})
 }
 
 func (me *app) publishView(ctx context.Context, msg *slack.Msg, in messageOptions) error {
-	client, err := me.makeClientFor(in.TeamID)
+	client, err := me.clientFor(ctx, in)
 	if err != nil {
 		return err
 	}
 
-	meta, err := json.Marshal(msg.Metadata)
+	encodedMeta, err := me.encodeMetadata(ctx, msg.Metadata, maxViewMetadataSize)
+	if err != nil {
+		return err
+	}
+	meta, err := json.Marshal(encodedMeta)
 	if err != nil {
 		return fmt.Errorf("failed to marshal metadata: %w", err)
 	}
 
 	me.LogDebugf("publishing view: %+v", msg)
-	_, err = client.PublishViewContext(ctx, in.UserID, slack.HomeTabViewRequest{
-		Type:            slack.VTHomeTab,
-		Blocks:          msg.Blocks,
-		PrivateMetadata: string(meta),
-	}, "")
-	return err
+	return me.withRetry(ctx, in.TeamID, "views.publish", true, func() error {
+		_, err := client.PublishViewContext(ctx, in.UserID, slack.HomeTabViewRequest{
+			Type:            slack.VTHomeTab,
+			Blocks:          msg.Blocks,
+			PrivateMetadata: string(meta),
+		}, "")
+		return err
+	})
 }
 
-func (me *app) openView(ctx context.Context, msg *slack.Msg, modalCfg ModalConfig, triggerID string, in messageOptions) error {
-	client, err := me.makeClientFor(in.TeamID)
+func (me *app) modalViewRequest(ctx context.Context, msg *slack.Msg, modalCfg ModalConfig) (*slack.ModalViewRequest, error) {
+	encodedMeta, err := me.encodeMetadata(ctx, msg.Metadata, maxViewMetadataSize)
 	if err != nil {
-		return err
+		return nil, err
 	}
-
-	meta, err := json.Marshal(msg.Metadata)
+	meta, err := json.Marshal(encodedMeta)
 	if err != nil {
-		return fmt.Errorf("failed to marshal metadata: %w", err)
+		return nil, fmt.Errorf("failed to marshal metadata: %w", err)
 	}
 
-	me.LogDebugf("opening view: %+v", msg)
-	_, err = client.OpenViewContext(ctx, triggerID, slack.ModalViewRequest{
+	return &slack.ModalViewRequest{
 		Type:            slack.VTModal,
 		Title:           modalCfg.Title,
 		Close:           modalCfg.Close,
@@ -132,17 +279,106 @@ func (me *app) openView(ctx context.Context, msg *slack.Msg, modalCfg ModalConfi
 		NotifyOnClose:   modalCfg.NotifyOnClose,
 		Blocks:          msg.Blocks,
 		PrivateMetadata: string(meta),
+	}, nil
+}
+
+func (me *app) openView(ctx context.Context, msg *slack.Msg, modalCfg ModalConfig, triggerID string, in messageOptions) error {
+	client, err := me.clientFor(ctx, in)
+	if err != nil {
+		return err
+	}
+
+	view, err := me.modalViewRequest(ctx, msg, modalCfg)
+	if err != nil {
+		return err
+	}
+
+	me.LogDebugf("opening view: %+v", msg)
+	// trigger IDs can only be used once
+	return me.withRetry(ctx, in.TeamID, "views.open", false, func() error {
+		_, err := client.OpenViewContext(ctx, triggerID, *view)
+		return err
 	})
-	return err
 }
 
-func (me *app) tokenExchange(ctx context.Context, code, clientID, clientSecret string) (*slack.OAuthV2Response, error) {
-	resp, err := slack.GetOAuthV2Response(http.DefaultClient, clientID, clientSecret, code, "")
+func (me *app) pushView(ctx context.Context, msg *slack.Msg, modalCfg ModalConfig, triggerID string, in messageOptions) error {
+	client, err := me.clientFor(ctx, in)
 	if err != nil {
-		return nil, fmt.Errorf("failed to exchange code for token: %w", err)
+		return err
+	}
+
+	view, err := me.modalViewRequest(ctx, msg, modalCfg)
+	if err != nil {
+		return err
+	}
+
+	me.LogDebugf("pushing view: %+v", msg)
+	return me.withRetry(ctx, in.TeamID, "views.push", false, func() error {
+		_, err := client.PushViewContext(ctx, triggerID, *view)
+		return err
+	})
+}
+
+func (me *app) updateView(ctx context.Context, msg *slack.Msg, modalCfg ModalConfig, viewID, hash string, in messageOptions) error {
+	client, err := me.clientFor(ctx, in)
+	if err != nil {
+		return err
+	}
+
+	view, err := me.modalViewRequest(ctx, msg, modalCfg)
+	if err != nil {
+		return err
 	}
-	if !resp.Ok {
-		return nil, fmt.Errorf("failed to exchange code for token: %s", resp.Error)
+
+	me.LogDebugf("updating view: %+v", msg)
+	return me.withRetry(ctx, in.TeamID, "views.update", true, func() error {
+		_, err := client.UpdateViewContext(ctx, *view, "", hash, viewID)
+		return err
+	})
+}
+
+func (me *app) tokenExchange(ctx context.Context, code, clientID, clientSecret, redirectURL string) (*slack.OAuthV2Response, error) {
+	resp, err := me.oauthV2Access(ctx, url.Values{
+		"client_id":     {clientID},
+		"client_secret": {clientSecret},
+		"code":          {code},
+		"redirect_uri":  {redirectURL},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to exchange code for token: %w", err)
 	}
 	return resp, nil
 }
+
+// oauthV2Access is used instead of the helpers of slack-go, which always call slack.com and would skip `Options.APIURL`
+func (me *app) oauthV2Access(ctx context.Context, values url.Values) (*slack.OAuthV2Response, error) {
+	apiURL := slack.APIURL
+	if me.opts.APIURL != "" {
+		apiURL = me.opts.APIURL
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, apiURL+"oauth.v2.access", strings.NewReader(values.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	res, err := me.httpClient().Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode == http.StatusTooManyRequests {
+		retryAfter, _ := strconv.Atoi(res.Header.Get("Retry-After"))
+		return nil, &slack.RateLimitedError{RetryAfter: time.Duration(retryAfter) * time.Second}
+	}
+	if res.StatusCode != http.StatusOK {
+		return nil, slack.StatusCodeError{Code: res.StatusCode, Status: res.Status}
+	}
+
+	var resp slack.OAuthV2Response
+	err = json.NewDecoder(res.Body).Decode(&resp)
+	if err != nil {
+		return nil, fmt.Errorf("invalid oauth.v2.access response: %w", err)
+	}
+	return &resp, resp.Err()
+}
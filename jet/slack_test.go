@@ -0,0 +1,117 @@
+package jet
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/slack-go/slack"
+)
+
+func TestClientCache(t *testing.T) {
+	var cache clientCache
+	created := 0
+	create := func() *slack.Client {
+		created++
+		return slack.New("token")
+	}
+
+	first := cache.get("xoxb-1", create)
+	if cache.get("xoxb-1", create) != first || created != 1 {
+		t.Fatalf("expected the client to be reused, created %d", created)
+	}
+	if cache.get("xoxb-2", create) == first || created != 2 {
+		t.Fatalf("expected a client per token, created %d", created)
+	}
+	cache.forget("xoxb-1")
+	if cache.get("xoxb-1", create) == first || created != 3 {
+		t.Fatalf("expected a new client once forgotten, created %d", created)
+	}
+}
+
+func TestClientFactory(t *testing.T) {
+	type call struct {
+		teamID string
+		token  string
+	}
+	var calls []call
+	built := NewBuilder().Build(Options{
+		Credentials: Credentials{
+			GetAccessToken: func(teamID string) (string, error) {
+				return "xoxb-" + teamID, nil
+			},
+		},
+		ClientFactory: func(teamID, token string) *slack.Client {
+			calls = append(calls, call{teamID: teamID, token: token})
+			return slack.New(token)
+		},
+	})
+
+	for _, teamID := range []string{"T1", "T1", "T2"} {
+		_, err := built.SlackAPI(teamID, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	expected := []call{{teamID: "T1", token: "xoxb-T1"}, {teamID: "T2", token: "xoxb-T2"}}
+	if len(calls) != len(expected) || calls[0] != expected[0] || calls[1] != expected[1] {
+		t.Errorf("expected the factory to be called once per token, got %+v", calls)
+	}
+}
+
+func TestOAuthUsesAPIURL(t *testing.T) {
+	var forms []map[string]string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/oauth.v2.access" {
+			http.NotFound(w, r)
+			return
+		}
+		err := r.ParseForm()
+		if err != nil {
+			t.Error(err)
+		}
+		forms = append(forms, map[string]string{
+			"code":          r.PostForm.Get("code"),
+			"refresh_token": r.PostForm.Get("refresh_token"),
+		})
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"ok":            true,
+			"access_token":  "xoxe.xoxb-new",
+			"refresh_token": "xoxe-new",
+			"expires_in":    43200,
+			"team":          map[string]any{"id": "T1"},
+		})
+	}))
+	defer srv.Close()
+
+	store := NewMemoryInstallationStore()
+	built := NewBuilder().Build(Options{
+		APIURL:            srv.URL + "/api/",
+		InstallationStore: store,
+		OAuthConfig:       &OAuthConfig{ClientID: "client", ClientSecret: "secret"},
+	}).(*app)
+
+	ctx := context.Background()
+	resp, err := built.tokenExchange(ctx, "code", "client", "secret", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.AccessToken != "xoxe.xoxb-new" || resp.Team.ID != "T1" {
+		t.Errorf("unexpected exchange response: %+v", resp)
+	}
+
+	refreshed, err := built.refreshToken(ctx, Installation{TeamID: "T1", BotToken: "xoxe.xoxb-old", BotRefreshToken: "xoxe-old"}, false, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if refreshed.BotToken != "xoxe.xoxb-new" {
+		t.Errorf("unexpected refreshed installation: %+v", refreshed)
+	}
+
+	if len(forms) != 2 || forms[0]["code"] != "code" || forms[1]["refresh_token"] != "xoxe-old" {
+		t.Errorf("expected both calls to reach the API URL, got %+v", forms)
+	}
+}
@@ -0,0 +1,120 @@
+package jet
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sync"
+)
+
+var ErrStateNotFound = errors.New("state not found")
+
+// StateStore is used to keep the flow state outside of Slack when it doesn't fit in the metadata.
+// Only a short reference to the key is then stored in the message/view.
+type StateStore interface {
+	// must return ErrStateNotFound if the key doesn't exist
+	Get(ctx context.Context, key string) ([]byte, error)
+	Put(ctx context.Context, key string, value []byte) error
+	Delete(ctx context.Context, key string) error
+}
+
+type memoryStateStore struct {
+	lock   sync.RWMutex
+	values map[string][]byte
+}
+
+// NewMemoryStateStore creates a StateStore which keeps everything in memory, the state will be lost when the process exits.
+func NewMemoryStateStore() StateStore {
+	return &memoryStateStore{
+		values: make(map[string][]byte),
+	}
+}
+
+func (me *memoryStateStore) Get(ctx context.Context, key string) ([]byte, error) {
+	me.lock.RLock()
+	defer me.lock.RUnlock()
+	value, found := me.values[key]
+	if !found {
+		return nil, ErrStateNotFound
+	}
+	return value, nil
+}
+
+func (me *memoryStateStore) Put(ctx context.Context, key string, value []byte) error {
+	me.lock.Lock()
+	defer me.lock.Unlock()
+	me.values[key] = value
+	return nil
+}
+
+func (me *memoryStateStore) Delete(ctx context.Context, key string) error {
+	me.lock.Lock()
+	defer me.lock.Unlock()
+	delete(me.values, key)
+	return nil
+}
+
+type fileStateStore struct {
+	dir string
+}
+
+// NewFileStateStore creates a StateStore which keeps every state as a file in the given directory.
+func NewFileStateStore(dir string) StateStore {
+	return &fileStateStore{
+		dir: dir,
+	}
+}
+
+var validStateKey = regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
+
+func (me *fileStateStore) path(key string) (string, error) {
+	if !validStateKey.MatchString(key) {
+		return "", fmt.Errorf("invalid state key: %q", key)
+	}
+	return filepath.Join(me.dir, key+".json"), nil
+}
+
+func (me *fileStateStore) Get(ctx context.Context, key string) ([]byte, error) {
+	path, err := me.path(key)
+	if err != nil {
+		return nil, err
+	}
+	value, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrStateNotFound
+	}
+	return value, err
+}
+
+func (me *fileStateStore) Put(ctx context.Context, key string, value []byte) error {
+	path, err := me.path(key)
+	if err != nil {
+		return err
+	}
+	err = os.MkdirAll(me.dir, 0o700)
+	if err != nil {
+		return err
+	}
+	// write to a temporary file first so readers never see a partial state
+	tmp := path + ".tmp"
+	err = os.WriteFile(tmp, value, 0o600)
+	if err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+func (me *fileStateStore) Delete(ctx context.Context, key string) error {
+	path, err := me.path(key)
+	if err != nil {
+		return err
+	}
+	err = os.Remove(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return err
+}
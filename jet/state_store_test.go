@@ -0,0 +1,62 @@
+package jet
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestStateStores(t *testing.T) {
+	stores := map[string]StateStore{
+		"memory": NewMemoryStateStore(),
+		"file":   NewFileStateStore(t.TempDir()),
+	}
+	for name, store := range stores {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			_, err := store.Get(ctx, "missing")
+			if !errors.Is(err, ErrStateNotFound) {
+				t.Fatalf("expected ErrStateNotFound, got %v", err)
+			}
+
+			for _, value := range [][]byte{[]byte(`{"f":"first"}`), []byte(`{"f":"second"}`)} {
+				err = store.Put(ctx, "key", value)
+				if err != nil {
+					t.Fatal(err)
+				}
+				stored, err := store.Get(ctx, "key")
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !bytes.Equal(stored, value) {
+					t.Fatalf("expected %s, got %s", value, stored)
+				}
+			}
+
+			err = store.Delete(ctx, "key")
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, err = store.Get(ctx, "key")
+			if !errors.Is(err, ErrStateNotFound) {
+				t.Fatalf("expected ErrStateNotFound after Delete, got %v", err)
+			}
+			err = store.Delete(ctx, "key")
+			if err != nil {
+				t.Fatalf("expected deleting a missing state to succeed, got %v", err)
+			}
+		})
+	}
+}
+
+func TestFileStateStoreRejectsInvalidKeys(t *testing.T) {
+	store := NewFileStateStore(t.TempDir())
+	ctx := context.Background()
+	for _, key := range []string{"../escape", "a/b", ""} {
+		err := store.Put(ctx, key, []byte("{}"))
+		if err == nil {
+			t.Errorf("expected key %q to be rejected", key)
+		}
+	}
+}
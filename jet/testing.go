@@ -0,0 +1,165 @@
+package jet
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+
+	"github.com/slack-go/slack"
+)
+
+type TestRenderOptions struct {
+	// Used by the hooks, defaults to context.Background()
+	Context context.Context
+	Source  SourceInfo
+	// Overrides the initial value of the states, indexed by hook (in order of declaration in the flow)
+	State map[int]any
+	// Run the start effects (UseEffectAtStart) after the initial render, they are skipped by default
+	RunStartEffects bool
+}
+
+// TestRender is the result of rendering a flow without Slack, see RenderForTest.
+type TestRender struct {
+	// What the flow returned
+	Rendered *RenderedFlow
+	// What would be sent to Slack
+	Message *Message
+	// The callback IDs registered by the flow (UseCallback and UseOptionsLoader), in order
+	CallbackIDs []string
+	// The metadata as Slack would send it back
+	Metadata slack.SlackMetadata
+
+	flow *Flow
+	opts TestRenderOptions
+}
+
+// RenderForTest renders a flow with the given props without needing a backend.
+// The result can be used to fire callbacks and render the flow again, like it would happen on Slack.
+func RenderForTest(flow Flow, props FlowProps, opts *TestRenderOptions) (*TestRender, error) {
+	opt := TestRenderOptions{}
+	if opts != nil {
+		opt = *opts
+	}
+	if opt.Context == nil {
+		opt.Context = context.Background()
+	}
+
+	rctx, err := newRenderContext(opt.Context, flow.name, props, nil, opt.Source, nil)
+	if err != nil {
+		return nil, err
+	}
+	res, err := newTestRender(&flow, opt, rctx, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(opt.State) == 0 && (!opt.RunStartEffects || len(rctx.pendingStartEffects) == 0) {
+		return res, nil
+	}
+	return res.rerender(func(rctx *renderContext) error {
+		for idx, value := range opt.State {
+			raw, err := json.Marshal(value)
+			if err != nil {
+				return fmt.Errorf("failed to marshal state %d: %w", idx, err)
+			}
+			err = rctx.updateState(idx, raw)
+			if err != nil {
+				return err
+			}
+		}
+		if !opt.RunStartEffects {
+			return nil
+		}
+		for _, effect := range rctx.pendingStartEffects {
+			err := effect(rctx)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Trigger fires the callback with the given action ID (as if the user clicked on it) and returns the new render.
+func (me *TestRender) Trigger(actionID string, action slack.BlockAction) (*TestRender, error) {
+	if action.ActionID == "" {
+		action.ActionID = actionID
+	}
+	return me.rerender(func(rctx *renderContext) error {
+		return rctx.triggerCallback(actionID, action)
+	})
+}
+
+// LoadOptions calls the options loader with the given action ID, as if the user typed the query.
+func (me *TestRender) LoadOptions(actionID, query string) ([]*slack.OptionBlockObject, error) {
+	meta, err := deserializeMetadata(&me.Metadata, "", nil)
+	if err != nil {
+		return nil, err
+	}
+	return me.flow.loadOptions(me.opts.Context, meta, me.opts.Source, actionID, query)
+}
+
+// BlocksJSON returns the blocks in the format used by the Block Kit Builder, which is convenient for snapshots.
+func (me *TestRender) BlocksJSON() ([]byte, error) {
+	return json.MarshalIndent(map[string]any{
+		"blocks": me.Message.Blocks.BlockSet,
+	}, "", "  ")
+}
+
+func (me *TestRender) rerender(betweenStages func(rctx *renderContext) error) (*TestRender, error) {
+	// the new render must not alter this one
+	prev := me.Metadata
+	prev.EventPayload = maps.Clone(prev.EventPayload)
+	meta, err := deserializeMetadata(&prev, "", nil)
+	if err != nil {
+		return nil, err
+	}
+	rctx, err := me.flow.hydrate(me.opts.Context, meta, me.opts.Source, nil)
+	if err != nil {
+		return nil, err
+	}
+	err = betweenStages(rctx)
+	if err != nil {
+		return nil, err
+	}
+	return newTestRender(me.flow, me.opts, rctx, meta)
+}
+
+func newTestRender(flow *Flow, opts TestRenderOptions, rctx *renderContext, meta *slackMetadataJet) (*TestRender, error) {
+	rendered, err := flow.renderBlocks(rctx)
+	if err != nil {
+		return nil, err
+	}
+	msg, err := flow.buildMessage(rctx, rendered, meta)
+	if err != nil {
+		return nil, err
+	}
+
+	// go through JSON so the metadata looks like the one coming from Slack
+	raw, err := json.Marshal(msg.Metadata)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal metadata: %w", err)
+	}
+	var metadata slack.SlackMetadata
+	err = json.Unmarshal(raw, &metadata)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal metadata: %w", err)
+	}
+
+	callbackIDs := []string{}
+	for _, hook := range rctx.expectedHooks {
+		if hook.callbackID != "" {
+			callbackIDs = append(callbackIDs, hook.callbackID)
+		}
+	}
+
+	return &TestRender{
+		Rendered:    rendered,
+		Message:     msg,
+		CallbackIDs: callbackIDs,
+		Metadata:    metadata,
+		flow:        flow,
+		opts:        opts,
+	}, nil
+}
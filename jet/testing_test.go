@@ -0,0 +1,83 @@
+package jet_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/LouisBrunner/jet/jet"
+	"github.com/slack-go/slack"
+)
+
+func TestRenderForTest(t *testing.T) {
+	counter := counterFlow("counter", nil)
+
+	render, err := jet.RenderForTest(counter, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if render.Rendered.Text != "count: 0" {
+		t.Errorf("expected the initial render, got %q", render.Rendered.Text)
+	}
+	if len(render.CallbackIDs) != 1 || render.CallbackIDs[0] != "jet_counter_cb_1" {
+		t.Fatalf("expected the callback of the button, got %v", render.CallbackIDs)
+	}
+
+	clicked, err := render.Trigger(render.CallbackIDs[0], slack.BlockAction{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	clicked, err = clicked.Trigger(render.CallbackIDs[0], slack.BlockAction{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if clicked.Rendered.Text != "count: 2" {
+		t.Errorf("expected the state to be kept between renders, got %q", clicked.Rendered.Text)
+	}
+	if render.Rendered.Text != "count: 0" {
+		t.Errorf("expected the previous render to be unchanged, got %q", render.Rendered.Text)
+	}
+
+	_, err = render.Trigger("jet_counter_cb_9", slack.BlockAction{})
+	if err == nil {
+		t.Error("expected unknown callbacks to fail")
+	}
+}
+
+func TestRenderForTestOptions(t *testing.T) {
+	loader := jet.NewFlow("loader", func(ctx jet.RenderContext, props jet.FlowProps) (*jet.RenderedFlow, error) {
+		status, setStatus, err := jet.UseState(ctx, "loading")
+		if err != nil {
+			return nil, err
+		}
+		err = jet.UseEffectAtStart(ctx, func(ctx context.Context) error {
+			return setStatus("loaded")
+		})
+		if err != nil {
+			return nil, err
+		}
+		return &jet.RenderedFlow{Text: fmt.Sprintf("%s for %s", status, ctx.Source().UserID)}, nil
+	}, &jet.FlowOptions{CanUpdateWithoutInteraction: true})
+
+	tests := []struct {
+		name     string
+		opts     *jet.TestRenderOptions
+		expected string
+	}{
+		{name: "defaults", expected: "loading for "},
+		{name: "source", opts: &jet.TestRenderOptions{Source: jet.SourceInfo{UserID: "U1"}}, expected: "loading for U1"},
+		{name: "state", opts: &jet.TestRenderOptions{State: map[int]any{0: "failed"}}, expected: "failed for "},
+		{name: "effects", opts: &jet.TestRenderOptions{RunStartEffects: true}, expected: "loaded for "},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			render, err := jet.RenderForTest(loader, nil, test.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if render.Rendered.Text != test.expected {
+				t.Errorf("expected %q, got %q", test.expected, render.Rendered.Text)
+			}
+		})
+	}
+}
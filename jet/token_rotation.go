@@ -0,0 +1,157 @@
+package jet
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"sync"
+	"time"
+)
+
+const defaultTokenRefreshMargin = 5 * time.Minute
+
+// tokenLocks serializes the refreshes of each installation, refresh tokens can only be used once
+type tokenLocks struct {
+	lock  sync.Mutex
+	locks map[string]*sync.Mutex
+}
+
+func (me *tokenLocks) get(key string) *sync.Mutex {
+	me.lock.Lock()
+	defer me.lock.Unlock()
+	if me.locks == nil {
+		me.locks = make(map[string]*sync.Mutex)
+	}
+	lock, found := me.locks[key]
+	if !found {
+		lock = &sync.Mutex{}
+		me.locks[key] = lock
+	}
+	return lock
+}
+
+func (me *app) tokenRefreshMargin() time.Duration {
+	if me.opts.OAuthConfig != nil && me.opts.OAuthConfig.TokenRefreshMargin > 0 {
+		return me.opts.OAuthConfig.TokenRefreshMargin
+	}
+	return defaultTokenRefreshMargin
+}
+
+// rotatingToken gives access to the bot or the user token of an installation, which are refreshed the same way
+type rotatingToken struct {
+	token     *string
+	refresh   *string
+	expiresAt *time.Time
+}
+
+func (me *Installation) rotatingToken(user bool) rotatingToken {
+	if user {
+		return rotatingToken{&me.UserToken, &me.UserRefreshToken, &me.UserTokenExpiresAt}
+	}
+	return rotatingToken{&me.BotToken, &me.BotRefreshToken, &me.BotTokenExpiresAt}
+}
+
+func (me *app) needsRefresh(token rotatingToken, now time.Time) bool {
+	if *token.refresh == "" || token.expiresAt.IsZero() {
+		return false
+	}
+	return now.Add(me.tokenRefreshMargin()).After(*token.expiresAt)
+}
+
+func (me *app) findInstallation(ctx context.Context, key *Installation) (*Installation, error) {
+	if key.IsEnterpriseInstall {
+		return me.opts.InstallationStore.FindByEnterprise(ctx, key.EnterpriseID)
+	}
+	return me.opts.InstallationStore.FindByTeam(ctx, key.TeamID)
+}
+
+// botToken returns the bot token of the installation, refreshing it first if it is about to expire (when token rotation is enabled)
+func (me *app) botToken(ctx context.Context, installation *Installation) (string, error) {
+	return me.installationToken(ctx, installation, false)
+}
+
+// userToken is the same as botToken but for the token of the user who installed the app
+func (me *app) userToken(ctx context.Context, installation *Installation) (string, error) {
+	if installation.UserToken == "" {
+		return "", fmt.Errorf("installation %q has no user token, request user scopes in `OAuthConfig.UserScopes`", installation.key())
+	}
+	return me.installationToken(ctx, installation, true)
+}
+
+func (me *app) installationToken(ctx context.Context, installation *Installation, user bool) (string, error) {
+	if !me.needsRefresh(installation.rotatingToken(user), time.Now()) {
+		return *installation.rotatingToken(user).token, nil
+	}
+
+	// the bot and user tokens share the lock as they are saved in the same installation
+	lock := me.tokenLocks.get(installation.key())
+	lock.Lock()
+	defer lock.Unlock()
+
+	// another call might have refreshed it while we were waiting
+	current, err := me.findInstallation(ctx, installation)
+	if err != nil {
+		return "", err
+	}
+	token := current.rotatingToken(user)
+	now := time.Now()
+	if !me.needsRefresh(token, now) {
+		return *token.token, nil
+	}
+
+	previous := *token.token
+	refreshed, err := me.refreshToken(ctx, *current, user, now)
+	if err != nil {
+		if now.Before(*token.expiresAt) {
+			me.LogErrorf("failed to refresh token for %q, using the current one until it expires: %+v", current.key(), err)
+			return previous, nil
+		}
+		return "", err
+	}
+	me.clients.forget(previous)
+	return *refreshed.rotatingToken(user).token, nil
+}
+
+func (me *app) refreshToken(ctx context.Context, installation Installation, user bool, now time.Time) (*Installation, error) {
+	cfg := me.opts.OAuthConfig
+	if cfg == nil {
+		return nil, errors.New("refreshing tokens requires `OAuthConfig`")
+	}
+
+	token := installation.rotatingToken(user)
+	resp, err := me.oauthV2Access(ctx, url.Values{
+		"client_id":     {cfg.ClientID},
+		"client_secret": {cfg.ClientSecret},
+		"refresh_token": {*token.refresh},
+		"grant_type":    {"refresh_token"},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to refresh token: %w", err)
+	}
+
+	*token.token = resp.AccessToken
+	*token.refresh = resp.RefreshToken
+	// no expiry means the new token doesn't expire (e.g. rotation was turned off), it must not be refreshed again
+	*token.expiresAt = time.Time{}
+	if resp.ExpiresIn > 0 {
+		*token.expiresAt = now.Add(time.Duration(resp.ExpiresIn) * time.Second)
+	}
+	err = me.opts.InstallationStore.Save(ctx, installation)
+	if err != nil {
+		// the previous refresh token is no longer valid, the installation must be saved
+		return nil, fmt.Errorf("failed to save refreshed installation: %w", err)
+	}
+	return &installation, nil
+}
+
+func (me *app) UserAccessToken(ctx context.Context, teamID, enterpriseID string) (string, error) {
+	if me.opts.InstallationStore == nil {
+		return "", errors.New("user tokens require an InstallationStore")
+	}
+	installation, err := me.lookupInstallation(ctx, teamID, enterpriseID)
+	if err != nil {
+		return "", err
+	}
+	return me.userToken(ctx, installation)
+}
@@ -0,0 +1,185 @@
+package jet
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type roundTripperFunc func(r *http.Request) (*http.Response, error)
+
+func (me roundTripperFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return me(r)
+}
+
+// fakeTokenRefresher answers `oauth.v2.access` with a new token derived from the refresh token
+func fakeTokenRefresher(calls *atomic.Int32, expiresIn int) *http.Client {
+	return &http.Client{
+		Transport: roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+			if !strings.HasSuffix(r.URL.Path, "/oauth.v2.access") {
+				return nil, fmt.Errorf("unexpected request: %s", r.URL)
+			}
+			raw, err := io.ReadAll(r.Body)
+			if err != nil {
+				return nil, err
+			}
+			form, err := url.ParseQuery(string(raw))
+			if err != nil {
+				return nil, err
+			}
+			calls.Add(1)
+			body, err := json.Marshal(map[string]any{
+				"ok":            true,
+				"access_token":  "new-" + form.Get("refresh_token"),
+				"refresh_token": "next-" + form.Get("refresh_token"),
+				"expires_in":    expiresIn,
+			})
+			if err != nil {
+				return nil, err
+			}
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Header:     http.Header{"Content-Type": {"application/json"}},
+				Body:       io.NopCloser(strings.NewReader(string(body))),
+			}, nil
+		}),
+	}
+}
+
+func newRotationApp(t *testing.T, installation Installation, expiresIn int) (*app, InstallationStore, *atomic.Int32) {
+	t.Helper()
+	store := NewMemoryInstallationStore()
+	err := store.Save(context.Background(), installation)
+	if err != nil {
+		t.Fatal(err)
+	}
+	calls := &atomic.Int32{}
+	built := NewBuilder().Build(Options{
+		InstallationStore: store,
+		HTTPClient:        fakeTokenRefresher(calls, expiresIn),
+		OAuthConfig: &OAuthConfig{
+			ClientID:     "client",
+			ClientSecret: "secret",
+		},
+	})
+	return built.(*app), store, calls
+}
+
+func TestBotTokenIsRefreshedOnce(t *testing.T) {
+	app, store, calls := newRotationApp(t, Installation{
+		TeamID:            "T1",
+		BotToken:          "bot",
+		BotRefreshToken:   "bot-refresh",
+		BotTokenExpiresAt: time.Now().Add(time.Minute),
+	}, 43200)
+
+	var wg sync.WaitGroup
+	tokens := make([]string, 10)
+	for i := range tokens {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			token, err := app.accessToken(context.Background(), "T1", "")
+			if err != nil {
+				t.Error(err)
+			}
+			tokens[i] = token
+		}()
+	}
+	wg.Wait()
+
+	if calls.Load() != 1 {
+		t.Fatalf("expected 1 refresh, got %d", calls.Load())
+	}
+	for _, token := range tokens {
+		if token != "new-bot-refresh" {
+			t.Fatalf("unexpected token: %q", token)
+		}
+	}
+	saved, err := store.FindByTeam(context.Background(), "T1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if saved.BotRefreshToken != "next-bot-refresh" || !saved.BotTokenExpiresAt.After(time.Now().Add(time.Hour)) {
+		t.Fatalf("refreshed token not saved: %+v", saved)
+	}
+}
+
+func TestUserTokenIsRefreshed(t *testing.T) {
+	app, store, calls := newRotationApp(t, Installation{
+		TeamID:             "T1",
+		BotToken:           "bot",
+		UserToken:          "user",
+		UserRefreshToken:   "user-refresh",
+		UserTokenExpiresAt: time.Now().Add(-time.Minute),
+	}, 43200)
+
+	token, err := app.UserAccessToken(context.Background(), "T1", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token != "new-user-refresh" || calls.Load() != 1 {
+		t.Fatalf("unexpected token %q after %d refreshes", token, calls.Load())
+	}
+	saved, err := store.FindByTeam(context.Background(), "T1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if saved.UserToken != "new-user-refresh" || saved.UserRefreshToken != "next-user-refresh" || saved.BotToken != "bot" {
+		t.Fatalf("unexpected installation: %+v", saved)
+	}
+
+	// the new token is still valid, no refresh needed
+	token, err = app.UserAccessToken(context.Background(), "T1", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token != "new-user-refresh" || calls.Load() != 1 {
+		t.Fatalf("unexpected token %q after %d refreshes", token, calls.Load())
+	}
+}
+
+func TestRefreshedTokenWithoutExpiry(t *testing.T) {
+	app, store, calls := newRotationApp(t, Installation{
+		TeamID:            "T1",
+		BotToken:          "bot",
+		BotRefreshToken:   "bot-refresh",
+		BotTokenExpiresAt: time.Now().Add(-time.Minute),
+	}, 0)
+
+	for range 2 {
+		token, err := app.accessToken(context.Background(), "T1", "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if token != "new-bot-refresh" || calls.Load() != 1 {
+			t.Fatalf("unexpected token %q after %d refreshes", token, calls.Load())
+		}
+	}
+	saved, err := store.FindByTeam(context.Background(), "T1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !saved.BotTokenExpiresAt.IsZero() {
+		t.Fatalf("expected the token to never expire, got %v", saved.BotTokenExpiresAt)
+	}
+}
+
+func TestUserAccessTokenWithoutUserToken(t *testing.T) {
+	app, _, _ := newRotationApp(t, Installation{
+		TeamID:   "T1",
+		BotToken: "bot",
+	}, 43200)
+	_, err := app.UserAccessToken(context.Background(), "T1", "")
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+}
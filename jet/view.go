@@ -1,9 +1,219 @@
 package jet
 
-import "github.com/slack-go/slack"
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
 
-type ViewSubmittedHandler func(ctx Context, args slack.InteractionCallback) error
+	"github.com/slack-go/slack"
+)
+
+// ViewSubmittedHandler handles a modal submission, the returned response (if not nil) controls what happens to the modal stack.
+type ViewSubmittedHandler func(ctx Context, args slack.InteractionCallback) (*ViewResponse, error)
 
 type ViewSubmitted struct {
 	Handler ViewSubmittedHandler
 }
+
+type ViewClosedHandler func(ctx Context, args slack.InteractionCallback) error
+
+// ViewResponse is the `response_action` sent back to Slack after a view submission.
+type ViewResponse struct {
+	action slack.ViewResponseAction
+	msg    *Message
+	errors map[string]string
+}
+
+// UpdateView replaces the submitted modal with the given one (which must come from a modal flow).
+func UpdateView(msg *Message) *ViewResponse {
+	return &ViewResponse{
+		action: slack.RAUpdate,
+		msg:    msg,
+	}
+}
+
+// PushView adds the given modal (which must come from a modal flow) on top of the stack.
+func PushView(msg *Message) *ViewResponse {
+	return &ViewResponse{
+		action: slack.RAPush,
+		msg:    msg,
+	}
+}
+
+// ClearViews closes all the modals in the stack.
+func ClearViews() *ViewResponse {
+	return &ViewResponse{
+		action: slack.RAClear,
+	}
+}
+
+// ViewErrors are validation messages keyed by block ID, displayed under the matching input of a submitted modal.
+// They can be returned as an error from a ViewSubmittedHandler, in which case the modal is kept open with the user's input.
+type ViewErrors map[string]string
+
+func (me ViewErrors) Error() string {
+	blocks := make([]string, 0, len(me))
+	for blockID, msg := range me {
+		blocks = append(blocks, fmt.Sprintf("%s: %s", blockID, msg))
+	}
+	sort.Strings(blocks)
+	return fmt.Sprintf("invalid view submission (%s)", strings.Join(blocks, ", "))
+}
+
+// ViewResponseErrors keeps the modal open and displays the given errors.
+func ViewResponseErrors(viewErrors ViewErrors) *ViewResponse {
+	return &ViewResponse{
+		action: slack.RAErrors,
+		errors: viewErrors,
+	}
+}
+
+func (me *ViewResponse) toSlack(ctx context.Context, app *app) (*slack.ViewSubmissionResponse, error) {
+	res := &slack.ViewSubmissionResponse{
+		ResponseAction: me.action,
+		Errors:         me.errors,
+	}
+	if me.action != slack.RAUpdate && me.action != slack.RAPush {
+		return res, nil
+	}
+	if me.msg == nil || me.msg.modal == nil {
+		return nil, errors.New("message is not a modal")
+	}
+	view, err := app.modalViewRequest(ctx, &me.msg.Msg, *me.msg.modal)
+	if err != nil {
+		return nil, err
+	}
+	res.View = view
+	return res, nil
+}
+
+const viewStateTag = "jet"
+
+// viewStateValues maps the fields of data to the values found in the view state
+func viewStateValues(state *slack.ViewState, data any) (map[string]interface{}, error) {
+	if state == nil {
+		return nil, errors.New("missing view state")
+	}
+
+	t := reflect.TypeOf(data)
+	if t == nil || t.Kind() != reflect.Pointer || t.Elem().Kind() != reflect.Struct {
+		return nil, fmt.Errorf("expected a pointer to a struct, got %T", data)
+	}
+	t = t.Elem()
+
+	values := make(map[string]interface{})
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get(viewStateTag)
+		if tag == "" || tag == "-" {
+			continue
+		}
+		blockID, actionID, _ := strings.Cut(tag, ",")
+		action, found := findViewStateAction(state, blockID, actionID)
+		if !found {
+			continue
+		}
+		value := viewStateValue(action)
+		if value == nil {
+			continue
+		}
+		values[field.Name] = value
+	}
+	return values, nil
+}
+
+func findViewStateAction(state *slack.ViewState, blockID, actionID string) (slack.BlockAction, bool) {
+	block, found := state.Values[blockID]
+	if !found {
+		return slack.BlockAction{}, false
+	}
+	if actionID != "" {
+		action, found := block[actionID]
+		return action, found
+	}
+	if len(block) != 1 {
+		return slack.BlockAction{}, false
+	}
+	for _, action := range block {
+		return action, true
+	}
+	return slack.BlockAction{}, false
+}
+
+// viewStateValue returns the value of an input depending on its type, nil means it was left empty
+func viewStateValue(action slack.BlockAction) interface{} {
+	optionValues := func(options []slack.OptionBlockObject) []string {
+		values := make([]string, len(options))
+		for i, option := range options {
+			values[i] = option.Value
+		}
+		return values
+	}
+	nonEmpty := func(value string) interface{} {
+		if value == "" {
+			return nil
+		}
+		return value
+	}
+
+	switch string(action.Type) {
+	case slack.OptTypeStatic, slack.OptTypeExternal, string(slack.METRadioButtons):
+		return nonEmpty(action.SelectedOption.Value)
+	case slack.MultiOptTypeStatic, slack.MultiOptTypeExternal, string(slack.METCheckboxGroups):
+		return optionValues(action.SelectedOptions)
+	case slack.OptTypeUser:
+		return nonEmpty(action.SelectedUser)
+	case slack.MultiOptTypeUser:
+		return action.SelectedUsers
+	case slack.OptTypeConversations:
+		return nonEmpty(action.SelectedConversation)
+	case slack.MultiOptTypeConversations:
+		return action.SelectedConversations
+	case slack.OptTypeChannels:
+		return nonEmpty(action.SelectedChannel)
+	case slack.MultiOptTypeChannels:
+		return action.SelectedChannels
+	case string(slack.METDatepicker):
+		return nonEmpty(action.SelectedDate)
+	case string(slack.METTimepicker):
+		return nonEmpty(action.SelectedTime)
+	case string(slack.METDatetimepicker):
+		if action.SelectedDateTime == 0 {
+			return nil
+		}
+		return action.SelectedDateTime
+	default:
+		// plain_text_input, number_input, email_text_input, url_text_input, etc
+		return nonEmpty(action.Value)
+	}
+}
+
+const (
+	viewStateDateLayout = "2006-01-02"
+	viewStateTimeLayout = "15:04"
+)
+
+// viewStateTimeHook allows decoding date, time and datetime pickers into time.Time
+func viewStateTimeHook(from reflect.Type, to reflect.Type, data interface{}) (interface{}, error) {
+	if to != reflect.TypeOf(time.Time{}) {
+		return data, nil
+	}
+	switch value := data.(type) {
+	case int64:
+		return time.Unix(value, 0), nil
+	case string:
+		for _, layout := range []string{viewStateDateLayout, viewStateTimeLayout} {
+			parsed, err := time.Parse(layout, value)
+			if err == nil {
+				return parsed, nil
+			}
+		}
+		return nil, fmt.Errorf("invalid date/time: %q", value)
+	default:
+		return data, nil
+	}
+}
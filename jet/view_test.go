@@ -0,0 +1,168 @@
+package jet_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/LouisBrunner/jet/jet"
+	"github.com/LouisBrunner/jet/jet/jettest"
+	"github.com/slack-go/slack"
+)
+
+func modalFlow(name string, blocks ...slack.Block) jet.Flow {
+	return jet.NewFlow(name, func(ctx jet.RenderContext, props jet.FlowProps) (*jet.RenderedFlow, error) {
+		return &jet.RenderedFlow{
+			Blocks:   slack.Blocks{BlockSet: blocks},
+			ForModal: &jet.ModalConfig{Title: plainText(name), Submit: plainText("Submit")},
+		}, nil
+	}, nil)
+}
+
+type surveyAnswers struct {
+	Name string `jet:"name"`
+	Age  int    `jet:"age,age_input"`
+}
+
+func TestViewSubmission(t *testing.T) {
+	srv := jettest.NewServer()
+	defer srv.Close()
+
+	builder := jet.NewBuilder()
+	survey, err := builder.AddFlow(modalFlow("survey",
+		slack.NewInputBlock("name", plainText("Name"), nil, slack.NewPlainTextInputBlockElement(nil, "name_input")),
+		slack.NewInputBlock("age", plainText("Age"), nil, slack.NewPlainTextInputBlockElement(nil, "age_input")),
+	))
+	if err != nil {
+		t.Fatal(err)
+	}
+	thanks, err := builder.AddFlow(modalFlow("thanks"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var answers surveyAnswers
+	app := builder.AddSlash("/survey", func(ctx jet.Context, args slack.SlashCommand) (*jet.Message, error) {
+		msg, err := ctx.StartFlow(survey, nil)
+		if err != nil {
+			return nil, err
+		}
+		return nil, ctx.OpenModal(msg, "")
+	}).HandleSubmittedView("survey", func(ctx jet.Context, args slack.InteractionCallback) (*jet.ViewResponse, error) {
+		answers = surveyAnswers{}
+		err := jet.DecodeViewState(args, &answers)
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case answers.Age < 18:
+			return nil, jet.ViewErrors{"age": "Too young"}
+		case answers.Name == "update":
+			msg, err := ctx.StartFlow(thanks, nil)
+			return jet.UpdateView(msg), err
+		case answers.Name == "push":
+			msg, err := ctx.StartFlow(thanks, nil)
+			return jet.PushView(msg), err
+		default:
+			return jet.ClearViews(), nil
+		}
+	}).Build(srv.Options(jet.Options{}))
+
+	ctx := context.Background()
+	_, err = srv.SlashCommand(ctx, app, "/survey", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	views := srv.Views()
+	if len(views) != 1 {
+		t.Fatalf("expected the survey to be opened, got %d views", len(views))
+	}
+
+	tests := []struct {
+		name   string
+		age    string
+		action slack.ViewResponseAction
+		errors map[string]string
+		title  string
+	}{
+		{name: "Jane", age: "12", action: slack.RAErrors, errors: map[string]string{"age": "Too young"}},
+		{name: "update", age: "30", action: slack.RAUpdate, title: "thanks"},
+		{name: "push", age: "30", action: slack.RAPush, title: "thanks"},
+		{name: "Jane", age: "30", action: slack.RAClear},
+	}
+	for _, test := range tests {
+		t.Run(string(test.action), func(t *testing.T) {
+			res, err := srv.SubmitView(ctx, app, &views[0], map[string]map[string]slack.BlockAction{
+				"name": {"name_input": {Type: "plain_text_input", Value: test.name}},
+				"age":  {"age_input": {Type: "plain_text_input", Value: test.age}},
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if answers.Name != test.name {
+				t.Errorf("expected the name to be decoded as %q, got %q", test.name, answers.Name)
+			}
+			response, ok := res.(*slack.ViewSubmissionResponse)
+			if !ok {
+				t.Fatalf("expected a view submission response, got %T", res)
+			}
+			if response.ResponseAction != test.action {
+				t.Errorf("expected action %q, got %q", test.action, response.ResponseAction)
+			}
+			if len(response.Errors) != len(test.errors) || response.Errors["age"] != test.errors["age"] {
+				t.Errorf("expected errors %v, got %v", test.errors, response.Errors)
+			}
+			if test.title != "" && (response.View == nil || response.View.Title.Text != test.title) {
+				t.Errorf("expected the %q modal, got %+v", test.title, response.View)
+			}
+		})
+	}
+}
+
+func TestViewClosed(t *testing.T) {
+	srv := jettest.NewServer()
+	defer srv.Close()
+
+	var closedByHook, closedByHandler bool
+	builder := jet.NewBuilder()
+	survey, err := builder.AddFlow(jet.NewFlow("survey", func(ctx jet.RenderContext, props jet.FlowProps) (*jet.RenderedFlow, error) {
+		err := jet.UseOnClose(ctx, func(ctx context.Context, view slack.View) error {
+			closedByHook = true
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		return &jet.RenderedFlow{
+			ForModal: &jet.ModalConfig{Title: plainText("Survey"), NotifyOnClose: true},
+		}, nil
+	}, nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	app := builder.AddSlash("/survey", func(ctx jet.Context, args slack.SlashCommand) (*jet.Message, error) {
+		msg, err := ctx.StartFlow(survey, nil)
+		if err != nil {
+			return nil, err
+		}
+		return nil, ctx.OpenModal(msg, "")
+	}).HandleClosedView("survey", func(ctx jet.Context, args slack.InteractionCallback) error {
+		closedByHandler = true
+		return nil
+	}).Build(srv.Options(jet.Options{}))
+
+	ctx := context.Background()
+	_, err = srv.SlashCommand(ctx, app, "/survey", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	views := srv.Views()
+	if len(views) != 1 {
+		t.Fatalf("expected the survey to be opened, got %d views", len(views))
+	}
+	err = srv.CloseView(ctx, app, &views[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !closedByHook || !closedByHandler {
+		t.Errorf("expected both the hook (%v) and the handler (%v) to be called", closedByHook, closedByHandler)
+	}
+}
@@ -0,0 +1,199 @@
+package jet
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+)
+
+// WorkflowInputs contains the raw inputs of a workflow function, keyed by their name.
+type WorkflowInputs map[string]json.RawMessage
+
+// WorkflowFunctionHandler handles the execution of a custom step from Workflow Builder.
+// The execution stays pending until `Complete` or `Fail` is called, which can happen later (e.g. from a callback of a posted flow).
+// If the handler returns an error, the execution fails with it.
+type WorkflowFunctionHandler func(ctx WorkflowContext, inputs WorkflowInputs) error
+
+// TypedWorkflowFunction wraps a handler which expects its inputs decoded in a struct (using `json` tags).
+func TypedWorkflowFunction[T any](handler func(ctx WorkflowContext, inputs T) error) WorkflowFunctionHandler {
+	return func(ctx WorkflowContext, inputs WorkflowInputs) error {
+		raw, err := json.Marshal(inputs)
+		if err != nil {
+			return err
+		}
+		var data T
+		err = json.Unmarshal(raw, &data)
+		if err != nil {
+			return fmt.Errorf("invalid inputs for %q: %w", ctx.Function().CallbackID, err)
+		}
+		return handler(ctx, data)
+	}
+}
+
+// WorkflowFunction identifies the execution of a workflow function.
+// It can be stored in the props of a flow to complete the execution later with `App.CompleteWorkflowFunction`.
+type WorkflowFunction struct {
+	TeamID       string `json:"team_id"`
+	EnterpriseID string `json:"enterprise_id,omitempty"`
+	CallbackID   string `json:"callback_id"`
+	ExecutionID  string `json:"execution_id"`
+	// only valid during the execution, the token of the team is used otherwise
+	token string
+}
+
+type WorkflowContext interface {
+	Context
+	Function() WorkflowFunction
+	// posts the flow in the given channel (e.g. one from the inputs), its callbacks are handled like any other flow
+	StartFlowInChannel(channelID string, flow *FlowHandle, props FlowProps) error
+	Complete(outputs map[string]string) error
+	Fail(message string) error
+}
+
+type workflowContext struct {
+	*appContext
+	function  WorkflowFunction
+	completed bool
+}
+
+func (me *workflowContext) Function() WorkflowFunction {
+	return me.function
+}
+
+func (me *workflowContext) StartFlowInChannel(channelID string, flow *FlowHandle, props FlowProps) error {
+	appCtx := *me.appContext
+	appCtx.msgOpts.ChannelID = channelID
+	return appCtx.StartFlowAndPost(flow, props)
+}
+
+func (me *workflowContext) Complete(outputs map[string]string) error {
+	err := me.app.CompleteWorkflowFunction(me.Context, me.function, outputs)
+	if err != nil {
+		return err
+	}
+	me.completed = true
+	return nil
+}
+
+func (me *workflowContext) Fail(message string) error {
+	err := me.app.FailWorkflowFunction(me.Context, me.function, message)
+	if err != nil {
+		return err
+	}
+	me.completed = true
+	return nil
+}
+
+// functionExecutedEvent is parsed from the raw event, as the inputs are not always strings and the token is missing from `slackevents.FunctionExecutedEvent`
+type functionExecutedEvent struct {
+	Function struct {
+		CallbackID string `json:"callback_id"`
+	} `json:"function"`
+	Inputs              WorkflowInputs `json:"inputs"`
+	FunctionExecutionID string         `json:"function_execution_id"`
+	BotAccessToken      string         `json:"bot_access_token"`
+}
+
+func parseFunctionExecuted(event slackevents.EventsAPIEvent) (*functionExecutedEvent, error) {
+	cb, ok := event.Data.(*slackevents.EventsAPICallbackEvent)
+	if !ok || cb.InnerEvent == nil {
+		return nil, errors.New("invalid function_executed event")
+	}
+	var data functionExecutedEvent
+	err := json.Unmarshal(*cb.InnerEvent, &data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid function_executed event: %w", err)
+	}
+	return &data, nil
+}
+
+// functionCallbackID only looks at the callback ID, so events of other functions are ignored even if the rest can't be parsed
+func functionCallbackID(event slackevents.EventsAPIEvent) string {
+	cb, ok := event.Data.(*slackevents.EventsAPICallbackEvent)
+	if !ok || cb.InnerEvent == nil {
+		return ""
+	}
+	var data struct {
+		Function struct {
+			CallbackID string `json:"callback_id"`
+		} `json:"function"`
+	}
+	if json.Unmarshal(*cb.InnerEvent, &data) != nil {
+		return ""
+	}
+	return data.Function.CallbackID
+}
+
+func (me *app) handleFunctionExecuted(ctx context.Context, event slackevents.EventsAPIEvent) (bool, error) {
+	handler, found := me.workflowFunctions[functionCallbackID(event)]
+	if !found {
+		return false, nil
+	}
+	data, err := parseFunctionExecuted(event)
+	if err != nil {
+		return true, err
+	}
+
+	wctx := &workflowContext{
+		appContext: &appContext{
+			Context: ctx,
+			app:     me,
+			msgOpts: messageOptions{
+				TeamID:       event.TeamID,
+				EnterpriseID: event.EnterpriseID,
+				Token:        data.BotAccessToken,
+			},
+			source: SourceInfo{
+				TeamID:       event.TeamID,
+				EnterpriseID: event.EnterpriseID,
+			},
+		},
+		function: WorkflowFunction{
+			TeamID:       event.TeamID,
+			EnterpriseID: event.EnterpriseID,
+			CallbackID:   data.Function.CallbackID,
+			ExecutionID:  data.FunctionExecutionID,
+			token:        data.BotAccessToken,
+		},
+	}
+	err = handler(wctx, data.Inputs)
+	if err != nil && !wctx.completed {
+		failErr := me.FailWorkflowFunction(ctx, wctx.function, err.Error())
+		if failErr != nil {
+			me.LogErrorf("failed to fail workflow function %q: %+v", data.FunctionExecutionID, failErr)
+		}
+	}
+	return true, err
+}
+
+func (me *app) workflowClient(ctx context.Context, function WorkflowFunction) (*slack.Client, error) {
+	return me.clientFor(ctx, messageOptions{
+		TeamID:       function.TeamID,
+		EnterpriseID: function.EnterpriseID,
+		Token:        function.token,
+	})
+}
+
+func (me *app) CompleteWorkflowFunction(ctx context.Context, function WorkflowFunction, outputs map[string]string) error {
+	client, err := me.workflowClient(ctx, function)
+	if err != nil {
+		return err
+	}
+	return me.withRetry(ctx, function.TeamID, "functions.completeSuccess", true, func() error {
+		return client.FunctionCompleteSuccessContext(ctx, function.ExecutionID, slack.FunctionCompleteSuccessRequestOptionOutput(outputs))
+	})
+}
+
+func (me *app) FailWorkflowFunction(ctx context.Context, function WorkflowFunction, message string) error {
+	client, err := me.workflowClient(ctx, function)
+	if err != nil {
+		return err
+	}
+	return me.withRetry(ctx, function.TeamID, "functions.completeError", true, func() error {
+		return client.FunctionCompleteErrorContext(ctx, function.ExecutionID, message)
+	})
+}
@@ -0,0 +1,134 @@
+package jet_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/LouisBrunner/jet/jet"
+	"github.com/LouisBrunner/jet/jet/jettest"
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+)
+
+type approvalInputs struct {
+	Channel string   `json:"channel"`
+	Users   []string `json:"users"`
+}
+
+type approvalProps struct {
+	Function jet.WorkflowFunction `json:"function"`
+}
+
+func TestWorkflowFunctionCompletesFromCallback(t *testing.T) {
+	srv := jettest.NewServer()
+	defer srv.Close()
+
+	var app jet.App
+	builder := jet.NewBuilder()
+	approval, err := builder.AddFlow(jet.NewFlow("approval", func(ctx jet.RenderContext, props jet.FlowProps) (*jet.RenderedFlow, error) {
+		var p approvalProps
+		err := jet.UnmarshalProps(props, &p)
+		if err != nil {
+			return nil, err
+		}
+		approve, err := jet.UseCallback(ctx, func(ctx context.Context, args slack.BlockAction) error {
+			return app.CompleteWorkflowFunction(ctx, p.Function, map[string]string{"approved": "yes"})
+		})
+		if err != nil {
+			return nil, err
+		}
+		return &jet.RenderedFlow{
+			Text: "approve?",
+			Blocks: slack.Blocks{BlockSet: []slack.Block{
+				slack.NewActionBlock("actions", slack.NewButtonBlockElement(approve, "", slack.NewTextBlockObject(slack.PlainTextType, "Approve", false, false))),
+			}},
+		}, nil
+	}, nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	builder.AddWorkflowFunction("approve", jet.TypedWorkflowFunction(func(ctx jet.WorkflowContext, inputs approvalInputs) error {
+		if len(inputs.Users) != 2 {
+			return errors.New("expected users")
+		}
+		props, err := jet.MarshalProps(approvalProps{Function: ctx.Function()})
+		if err != nil {
+			return err
+		}
+		return ctx.StartFlowInChannel(inputs.Channel, approval, props)
+	}))
+	app = builder.Build(srv.Options(jet.Options{}))
+
+	ctx := context.Background()
+	executionID, err := srv.RunWorkflowFunction(ctx, app, "approve", map[string]any{
+		"channel": "C0APPROVAL",
+		"users":   []string{"U1", "U2"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result := srv.FunctionResult(executionID); result != nil {
+		t.Fatalf("expected the execution to be pending, got %+v", result)
+	}
+
+	msg := srv.LastMessage("C0APPROVAL")
+	if msg == nil {
+		t.Fatal("expected the flow to be posted")
+	}
+	_, err = srv.ClickButton(ctx, app, msg, "jet_approval_cb_0", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	result := srv.FunctionResult(executionID)
+	if result == nil || result.Error != "" || result.Outputs["approved"] != "yes" {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+}
+
+func TestWorkflowFunctionFailsOnError(t *testing.T) {
+	srv := jettest.NewServer()
+	defer srv.Close()
+
+	app := jet.NewBuilder().AddWorkflowFunction("broken", func(ctx jet.WorkflowContext, inputs jet.WorkflowInputs) error {
+		return errors.New("boom")
+	}).Build(srv.Options(jet.Options{}))
+
+	executionID, err := srv.RunWorkflowFunction(context.Background(), app, "broken", nil)
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	result := srv.FunctionResult(executionID)
+	if result == nil || result.Error != "boom" {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+}
+
+func TestWorkflowFunctionIgnoresUnknownFunctions(t *testing.T) {
+	handled := false
+	app := jet.NewBuilder().
+		AddWorkflowFunction("known", func(ctx jet.WorkflowContext, inputs jet.WorkflowInputs) error {
+			t.Fatal("unexpected call")
+			return nil
+		}).
+		HandleEvent(string(slackevents.FunctionExecuted), func(ctx jet.Context, event slackevents.EventsAPIEvent) error {
+			handled = true
+			return nil
+		}).
+		Build(jet.Options{})
+
+	// the inputs are invalid, which must not matter for functions of other apps
+	inner := json.RawMessage(`{"type":"function_executed","function":{"callback_id":"other"},"inputs":[]}`)
+	_, err := app.HandleEvent(context.Background(), slackevents.EventsAPIEvent{
+		Type:       slackevents.CallbackEvent,
+		Data:       &slackevents.EventsAPICallbackEvent{InnerEvent: &inner},
+		InnerEvent: slackevents.EventsAPIInnerEvent{Type: string(slackevents.FunctionExecuted)},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !handled {
+		t.Fatal("expected the generic event handler to be called")
+	}
+}